@@ -0,0 +1,141 @@
+package compare
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var typeByte = reflect.TypeFor[byte]()
+
+// isBytesType returns true if the type is []byte or [N]byte.
+func isBytesType(typ reflect.Type) bool {
+	return typ == typeByteSlice || typ.Kind() == reflect.Array && typ.Elem() == typeByte
+}
+
+// getBytes returns the bytes of a []byte or [N]byte value.
+//
+// A non-addressable array is copied byte by byte, which also works with a value obtained from an unexported field.
+func getBytes(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice || v.CanAddr() {
+		return v.Bytes()
+	}
+	b := make([]byte, v.Len())
+	for i := range b {
+		b[i] = byte(v.Index(i).Uint())
+	}
+	return b
+}
+
+// firstBytesDifference returns the offset of the first different byte of 2 byte slices.
+func firstBytesDifference(b1, b2 []byte) int {
+	i := 0
+	for i < len(b1) && i < len(b2) && b1[i] == b2[i] {
+		i++
+	}
+	return i
+}
+
+// hexWindow returns the hexadecimal encoding of a window of bytes around the offset.
+//
+// The encoding is limited to maxLength characters, the truncated parts are replaced by an ellipsis.
+// If maxLength is 0, the bytes are not truncated.
+func hexWindow(b []byte, offset int, maxLength int) string {
+	n := maxLength / 2
+	if maxLength <= 0 || len(b) <= n {
+		return hex.EncodeToString(b)
+	}
+	start := max(0, offset-n/2)
+	end := min(len(b), start+n)
+	start = max(0, end-n)
+	res := hex.EncodeToString(b[start:end])
+	if start > 0 {
+		res = stringEllipsis + res
+	}
+	if end < len(b) {
+		res += stringEllipsis
+	}
+	return res
+}
+
+const (
+	hexDumpRowSize  = 16
+	hexDumpLineSize = 1 + 8 + 2 + hexDumpRowSize*5/2 + 1 + hexDumpRowSize + 1 // Prefix, offset, hex, ASCII, new line.
+	hexDigits       = "0123456789abcdef"
+)
+
+// hexDumpDiff returns an xxd-style hexadecimal dump of the rows that are different between 2 byte slices.
+//
+// The rows of b1 are prefixed by "-", and the rows of b2 are prefixed by "+".
+// If maxRows is greater than 0, at most maxRows different rows are shown.
+func hexDumpDiff(b1, b2 []byte, maxRows int) string {
+	var sb strings.Builder
+	rows := (max(len(b1), len(b2)) + hexDumpRowSize - 1) / hexDumpRowSize
+	if maxRows > 0 {
+		rows = min(rows, maxRows)
+	}
+	sb.Grow(rows * 2 * hexDumpLineSize)
+	shown := 0
+	notShown := 0
+	for off := 0; off < max(len(b1), len(b2)); off += hexDumpRowSize {
+		r1 := hexDumpRow(b1, off)
+		r2 := hexDumpRow(b2, off)
+		if bytes.Equal(r1, r2) {
+			continue
+		}
+		if maxRows > 0 && shown >= maxRows {
+			notShown++
+			continue
+		}
+		shown++
+		if r1 != nil {
+			writeHexDumpLine(&sb, '-', off, r1)
+		}
+		if r2 != nil {
+			writeHexDumpLine(&sb, '+', off, r2)
+		}
+	}
+	if notShown > 0 {
+		_, _ = fmt.Fprintf(&sb, "\n... %d different rows not shown", notShown)
+	}
+	return sb.String()
+}
+
+// hexDumpRow returns the row of bytes at the offset, or nil if the offset is beyond the end.
+func hexDumpRow(b []byte, off int) []byte {
+	if off >= len(b) {
+		return nil
+	}
+	return b[off:min(len(b), off+hexDumpRowSize)]
+}
+
+func writeHexDumpLine(sb *strings.Builder, prefix byte, off int, row []byte) {
+	if sb.Len() > 0 {
+		sb.WriteByte('\n')
+	}
+	sb.WriteByte(prefix)
+	for shift := 28; shift >= 0; shift -= 4 {
+		sb.WriteByte(hexDigits[off>>shift&0xf])
+	}
+	sb.WriteString(": ")
+	for i := range hexDumpRowSize {
+		if i < len(row) {
+			sb.WriteByte(hexDigits[row[i]>>4])
+			sb.WriteByte(hexDigits[row[i]&0xf])
+		} else {
+			sb.WriteString("  ")
+		}
+		if i%2 == 1 {
+			sb.WriteByte(' ')
+		}
+	}
+	sb.WriteByte(' ')
+	for _, c := range row {
+		if c < 0x20 || c > 0x7e {
+			c = '.'
+		}
+		sb.WriteByte(c)
+	}
+}
@@ -3,13 +3,19 @@ package compare
 
 import (
 	"bytes"
+	"cmp"
+	"errors"
 	"fmt"
+	"iter"
+	"maps"
+	"math"
 	"reflect"
 	"runtime"
 	"slices"
 	"strconv"
-	"sync"
+	"strings"
 	"sync/atomic"
+	"unicode/utf8"
 
 	"github.com/pierrre/go-libs/reflectutil"
 	"github.com/pierrre/go-libs/strconvio"
@@ -22,13 +28,84 @@ func Compare(v1, v2 any) Result {
 	return DefaultComparator.Load().Compare(v1, v2)
 }
 
+// Equal returns true if 2 values are equal with [DefaultComparator].
+//
+// See [Comparator.Equal].
+func Equal(v1, v2 any) bool {
+	return DefaultComparator.Load().Equal(v1, v2)
+}
+
+// Differences returns an iterator over the differences between 2 values with [DefaultComparator].
+//
+// See [Comparator.Differences].
+func Differences(v1, v2 any) iter.Seq[Difference] {
+	return DefaultComparator.Load().Differences(v1, v2)
+}
+
+// CompareT compares 2 values of the same type with [DefaultComparator].
+//
+// See [CompareTWith].
+func CompareT[T any](v1, v2 T) Result {
+	return CompareTWith(DefaultComparator.Load(), v1, v2)
+}
+
+// CompareTWith compares 2 values of the same type with a [Comparator].
+//
+// It is the generic equivalent of [Comparator.Compare] (methods can't have type parameters).
+// The values are not converted to interfaces, and are copied to a pooled buffer, so it doesn't allocate.
+// If T is an interface type, the dynamic values are compared.
+func CompareTWith[T any](c *Comparator, v1, v2 T) Result {
+	p := getValuesPool[T]()
+	vs := p.Get()
+	defer p.Put(vs)
+	vs[0] = v1
+	vs[1] = v2
+	defer clear(vs[:])
+	rvs := reflect.ValueOf(vs).Elem()
+	return c.compareRootValues(unwrapInterface(rvs.Index(0)), unwrapInterface(rvs.Index(1)))
+}
+
+func unwrapInterface(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	return v
+}
+
+var valuesPools syncutil.Map[reflect.Type, any]
+
+func getValuesPool[T any]() *syncutil.Pool[*[2]T] {
+	typ := reflect.TypeFor[T]()
+	p, ok := valuesPools.Load(typ)
+	if !ok {
+		p, _ = valuesPools.LoadOrStore(typ, &syncutil.Pool[*[2]T]{
+			New: func() *[2]T {
+				return new([2]T)
+			},
+		})
+	}
+	return p.(*syncutil.Pool[*[2]T]) //nolint:forcetypeassert // The type is always correct.
+}
+
 // DefaultComparator is the default [Comparator].
 //
-// It is created with [NewComparator].
+// It is created with [NewComparator], and is frozen (see [Comparator.Freeze]).
+// In order to change it, store a new [Comparator], or use [SetDefaultComparator] in tests.
 var DefaultComparator atomic.Pointer[Comparator]
 
 func init() {
-	DefaultComparator.Store(NewComparator())
+	DefaultComparator.Store(NewComparator().Freeze())
+}
+
+// SetDefaultComparator sets [DefaultComparator] for the duration of a test.
+//
+// The previous [Comparator] is restored when the test ends.
+// As [DefaultComparator] is global, it must not be used in parallel tests.
+func SetDefaultComparator(tb interface{ Cleanup(f func()) }, c *Comparator) {
+	prev := DefaultComparator.Swap(c)
+	tb.Cleanup(func() {
+		DefaultComparator.Store(prev)
+	})
 }
 
 // Comparator compares 2 values.
@@ -36,69 +113,372 @@ func init() {
 // It should be created with [NewComparator].
 type Comparator struct {
 	// MaxDepth is the maximum depth of the comparison.
-	// If the value is reached, the comparison is stopped.
+	// If the value is reached, the comparison is stopped,
+	// and a difference "max depth reached" is reported.
 	// Default: 0 (no limit).
 	MaxDepth int
 	// SliceMaxDifferences is the maximum number of different items for a slice.
-	// If the value is reached, the comparison is stopped for the current slice.
+	// If the value is reached, the comparison is stopped for the current slice,
+	// and a difference with the number of items not compared is reported.
 	// It is also used for arrays.
 	// Setting it to 0 disables it.
 	// Default: 10.
 	SliceMaxDifferences int
 	// MapMaxDifferences is the maximum number of different items for a map.
-	// If the value is reached, the comparison is stopped for the current map.
+	// If the value is reached, the comparison is stopped for the current map,
+	// and a difference with the number of keys not compared is reported.
 	// Setting it to 0 disables it.
 	// Default: 10.
 	MapMaxDifferences int
+	// MaxDifferences is the maximum number of differences for the whole comparison.
+	// If the value is exceeded, the comparison is stopped,
+	// and a last difference "max differences reached" is added to the result.
+	// Default: 0 (no limit).
+	MaxDifferences int
 	// Funcs is the list of custom comparison functions.
-	// Default: []byte, reflect.Value, .Equal().
+	// Default: []byte, reflect.Value, .Equal(), .Cmp(), .Compare().
 	Funcs []Func
+	// TypeFuncs is the map of custom comparison functions by type.
+	// It is consulted before Funcs, with a single lookup.
+	// If a function returns "stop" false, Funcs is consulted.
+	// See also [RegisterType].
+	// Default: empty.
+	TypeFuncs map[reflect.Type]Func
+	// SliceUnordered compares all slices and arrays as multisets, the order of the elements is ignored.
+	// The elements are matched by deep equality, and only the elements without match are reported.
+	// See also UnorderedTypes, UnorderedPaths and the "unordered" struct tag option (see [TagName]).
+	// Default: false.
+	SliceUnordered bool
+	// UnorderedTypes is the set of slice and array types compared as multisets (see SliceUnordered).
+	// Default: empty.
+	UnorderedTypes map[reflect.Type]bool
+	// UnorderedPaths is the list of [PathPattern]s for which the slices and arrays are compared as multisets (see SliceUnordered).
+	// Default: empty.
+	UnorderedPaths []*PathPattern
+	// SliceKeys is the set of [SliceKey]s, by slice element type.
+	// The slices and arrays with an element type in this set are compared by key (see [SliceKey]).
+	// It has priority over SliceUnordered and SliceEditScript.
+	// Default: empty.
+	SliceKeys map[reflect.Type]SliceKey
+	// SliceEditScript compares slices with an edit script (Myers' diff algorithm), instead of index by index.
+	// The inserted and deleted elements are reported with their index in the slice that contains them as path.
+	// V1 and V2 contain the indexes in both slices, the index in the other slice is the position of the insertion or deletion.
+	// The elements replaced by other elements are compared recursively, with their index in v1 as path.
+	// If the indexes are different, the replacement is reported first, with the indexes in both slices in V1 and V2.
+	// The elements are matched without IgnorePaths, they are applied to the reported elements.
+	// It is not used for arrays, or if the slices are compared as multisets (see SliceUnordered).
+	// Default: false.
+	SliceEditScript bool
+	// StringMaxLength is the maximum length (in bytes) of the strings shown in V1 and V2.
+	// If a string is longer, the offset of the first difference is reported,
+	// and the strings are truncated to a window around it.
+	// Setting it to 0 disables it.
+	// Default: 1000.
+	StringMaxLength int
+	// StringDiff reports a line-based unified diff (see [Difference.Diff]) for multi-line strings.
+	// Default: true.
+	StringDiff bool
+	// StringDiffContext is the number of context lines of the unified diff (see StringDiff).
+	// Default: 3.
+	StringDiffContext int
+	// BytesDiff reports a line-based unified diff for multi-line []byte, instead of comparing the bytes one by one.
+	// It uses StringDiffContext.
+	// Default: false.
+	BytesDiff bool
+	// NilEmptyEqual makes nil and empty slices and maps equal.
+	// A nil value and a non-empty value are compared as an empty value and a non-empty value.
+	// Default: false.
+	NilEmptyEqual bool
+	// NilEmptyEqualTypes overrides NilEmptyEqual for the given slice and map types.
+	// Default: empty.
+	NilEmptyEqualTypes map[reflect.Type]bool
+	// NilZeroEqual makes nil pointers and interfaces equal to pointers to zero values and interfaces containing zero values.
+	// Default: false.
+	NilZeroEqual bool
+	// FloatTolerance is the tolerance used to compare floats and complexes.
+	// Default: zero (strict equality).
+	FloatTolerance FloatTolerance
+	// IgnorePaths is the list of [PathPattern]s for which the values are not compared.
+	// The ignored values are not visited, and are not counted in the max differences.
+	// The root is ignored if it matches, for example with ".".
+	// Default: empty.
+	IgnorePaths []*PathPattern
+
+	frozen *Comparator
+	plans  *syncutil.Map[reflect.Type, *typePlan]
 }
 
 // NewComparator returns a new [Comparator] initialized with default values.
-func NewComparator() *Comparator {
-	return &Comparator{
+//
+// The [Option]s are applied after the default values.
+func NewComparator(opts ...Option) *Comparator {
+	c := &Comparator{
 		SliceMaxDifferences: 10,
 		MapMaxDifferences:   10,
+		StringMaxLength:     1000,
+		StringDiff:          true,
+		StringDiffContext:   3,
 		Funcs: []Func{
 			NewBytesEqualFunc(),
 			NewReflectValueFunc(),
 			NewMethodEqualFunc(),
 			NewMethodCmpFunc(),
+			NewMethodCompareFunc(),
 		},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// With returns a new [Comparator] derived from this one, with the [Option]s applied.
+//
+// The current [Comparator] is not modified.
+// The new [Comparator] is not frozen.
+func (c *Comparator) With(opts ...Option) *Comparator {
+	nc := c.Clone()
+	for _, opt := range opts {
+		opt(nc)
+	}
+	return nc
+}
+
+// Clone returns a copy of the [Comparator].
+//
+// [Comparator.Funcs] is copied, so it can be modified without affecting the original.
+// The copy is not frozen.
+func (c *Comparator) Clone() *Comparator {
+	nc := *c
+	nc.Funcs = slices.Clone(c.Funcs)
+	nc.TypeFuncs = maps.Clone(c.TypeFuncs)
+	nc.UnorderedTypes = maps.Clone(c.UnorderedTypes)
+	nc.UnorderedPaths = slices.Clone(c.UnorderedPaths)
+	nc.SliceKeys = maps.Clone(c.SliceKeys)
+	nc.NilEmptyEqualTypes = maps.Clone(c.NilEmptyEqualTypes)
+	nc.IgnorePaths = slices.Clone(c.IgnorePaths)
+	nc.frozen = nil
+	nc.plans = nil
+	return &nc
+}
+
+// Freeze makes the [Comparator] read-only, and returns it.
+//
+// The [Comparator] must not be modified after this call.
+// The comparisons use a snapshot of the configuration taken by this call, so the modifications have no effect.
+// If a modification is detected when a comparison starts, it panics.
+// The functions are compared by code pointer, so replacing a closure by another closure created by the same function literal is not detected.
+// Use [Comparator.Clone] or [Comparator.With] to get a modifiable copy.
+//
+// A frozen [Comparator] caches a comparison plan for each type (which functions may apply, how to compare the kind),
+// so the comparison of the values of an already seen type is faster.
+func (c *Comparator) Freeze() *Comparator {
+	c.frozen = c.Clone()
+	c.plans = new(syncutil.Map[reflect.Type, *typePlan])
+	// The snapshot is frozen too, and is its own snapshot.
+	c.frozen.frozen = c.frozen
+	c.frozen.plans = c.plans
+	return c
+}
+
+// IsFrozen returns true if the [Comparator] is frozen (see [Comparator.Freeze]).
+func (c *Comparator) IsFrozen() bool {
+	return c.frozen != nil
+}
+
+func (c *Comparator) checkFrozen() {
+	if c.frozen != nil && !c.equalConfig(c.frozen) {
+		panic(errors.New("frozen Comparator was modified"))
+	}
+}
+
+// equalConfig returns true if the configuration of 2 [Comparator]s is the same.
+//
+// It must be updated when a field is added to [Comparator].
+func (c *Comparator) equalConfig(o *Comparator) bool {
+	return c.MaxDepth == o.MaxDepth &&
+		c.SliceMaxDifferences == o.SliceMaxDifferences &&
+		c.MapMaxDifferences == o.MapMaxDifferences &&
+		c.MaxDifferences == o.MaxDifferences &&
+		c.SliceUnordered == o.SliceUnordered &&
+		maps.Equal(c.UnorderedTypes, o.UnorderedTypes) &&
+		slices.Equal(c.UnorderedPaths, o.UnorderedPaths) &&
+		maps.EqualFunc(c.SliceKeys, o.SliceKeys, equalSliceKey) &&
+		c.SliceEditScript == o.SliceEditScript &&
+		c.StringMaxLength == o.StringMaxLength &&
+		c.StringDiff == o.StringDiff &&
+		c.StringDiffContext == o.StringDiffContext &&
+		c.BytesDiff == o.BytesDiff &&
+		c.NilEmptyEqual == o.NilEmptyEqual &&
+		maps.Equal(c.NilEmptyEqualTypes, o.NilEmptyEqualTypes) &&
+		c.NilZeroEqual == o.NilZeroEqual &&
+		c.FloatTolerance == o.FloatTolerance &&
+		slices.EqualFunc(c.Funcs, o.Funcs, equalFunc) &&
+		maps.EqualFunc(c.TypeFuncs, o.TypeFuncs, equalFunc) &&
+		slices.Equal(c.IgnorePaths, o.IgnorePaths)
+}
+
+func equalFunc(f1, f2 Func) bool {
+	return reflect.ValueOf(f1).Pointer() == reflect.ValueOf(f2).Pointer()
+}
+
+func equalSliceKey(k1, k2 SliceKey) bool {
+	return k1.Name == k2.Name && reflect.ValueOf(k1.Func).Pointer() == reflect.ValueOf(k2.Func).Pointer()
+}
+
+// getState returns the [Comparator] used for the comparison and a [State].
+//
+// If the [Comparator] is frozen, its snapshot is used.
+func (c *Comparator) getState() (*Comparator, *State) {
+	if c.frozen != nil {
+		c.checkFrozen()
+		c = c.frozen
+	}
+	st := statePool.Get()
+	st.reset(c)
+	return c, st
 }
 
 // Compare compares 2 values.
 func (c *Comparator) Compare(v1, v2 any) Result {
-	st := statePool.Get()
+	return c.compareRootValues(reflect.ValueOf(v1), reflect.ValueOf(v2))
+}
+
+func (c *Comparator) compareRootValues(v1, v2 reflect.Value) Result {
+	c, st := c.getState()
+	defer statePool.Put(st)
+	c.compareRoot(st, v1, v2)
+	r := st.result
+	st.result = nil
+	return r
+}
+
+// CompareValues compares 2 sub-values during a comparison, and returns the differences.
+//
+// It is intended to be called by a [Func], in order to delegate the comparison of sub-values to the normal deep comparison.
+// The paths of the returned differences are relative to the current value,
+// they can be prefixed with [Result.PrependPath].
+// The returned [Result] should be returned by the [Func], so the differences are reported.
+func (c *Comparator) CompareValues(st *State, v1, v2 reflect.Value) Result {
+	pathBase, result, yield, stopped, differences, maxDifferences := st.pathBase, st.result, st.yield, st.stopped, st.differences, st.maxDifferences
+	st.pathBase = len(st.path)
+	st.result = nil
+	st.yield = nil
+	st.maxDifferences = 0 // The maximum is checked when the Result is reported.
+	c.compare(st, v1, v2)
+	r := st.result
+	if st.equal && st.differences > differences {
+		// Only the equality is checked, the differences are not collected.
+		r = Result{{}}
+	}
+	st.pathBase, st.result, st.yield, st.stopped, st.differences, st.maxDifferences = pathBase, result, yield, stopped, differences, maxDifferences
+	return r
+}
+
+// Equal returns true if 2 values are equal.
+//
+// It stops at the first difference, and doesn't format the values, so it is faster than [Comparator.Compare].
+func (c *Comparator) Equal(v1, v2 any) bool {
+	c, st := c.getState()
 	defer statePool.Put(st)
-	st.reset()
-	return c.compare(
-		st,
-		reflect.ValueOf(v1),
-		reflect.ValueOf(v2),
-	)
+	st.equal = true
+	c.compareRoot(st, reflect.ValueOf(v1), reflect.ValueOf(v2))
+	return st.differences == 0
+}
+
+// Differences returns an iterator over the differences between 2 values.
+//
+// Each [Difference] is yielded as soon as it is found.
+// The comparison is stopped when the iteration is stopped.
+func (c *Comparator) Differences(v1, v2 any) iter.Seq[Difference] {
+	return func(yield func(Difference) bool) {
+		c, st := c.getState()
+		defer statePool.Put(st)
+		st.yield = yield
+		defer func() {
+			st.yield = nil
+		}()
+		c.compareRoot(st, reflect.ValueOf(v1), reflect.ValueOf(v2))
+	}
 }
 
-func (c *Comparator) compare(st *State, v1, v2 reflect.Value) Result {
+// compareRoot compares 2 root values.
+// The root is not compared if it matches [Comparator.IgnorePaths].
+func (c *Comparator) compareRoot(st *State, v1, v2 reflect.Value) {
+	if c.isIgnored(st) {
+		return
+	}
+	c.compare(st, v1, v2)
+}
+
+func (c *Comparator) compare(st *State, v1, v2 reflect.Value) {
+	if st.stopped {
+		return
+	}
 	if c.MaxDepth > 0 && st.Depth >= c.MaxDepth {
-		return nil
+		if !st.reportEqualOnly() {
+			st.report(Difference{
+				Kind:    DifferenceTruncated,
+				Message: msgMaxDepthReached,
+			})
+		}
+		return
 	}
 	st.Depth++
 	defer func() {
 		st.Depth--
 	}()
-	if r, stop := c.compareValid(v1, v2); stop {
-		return r
+	if c.compareValid(st, v1, v2) {
+		return
+	}
+	if c.compareType(st, v1, v2) {
+		return
+	}
+	if p := c.getPlan(v1.Type()); p != nil {
+		p.compare(c, st, v1, v2)
+		return
 	}
-	if r, stop := c.compareType(v1, v2); stop {
-		return r
+	if c.compareFuncs(st, v1, v2) {
+		return
 	}
-	if r, stop := c.compareFuncs(st, v1, v2); stop {
-		return r
+	c.compareKind(st, v1, v2)
+}
+
+// compareChild compares 2 child values at the given path step.
+// It returns true if differences were reported.
+func (c *Comparator) compareChild(st *State, ps pathStep, v1, v2 reflect.Value) bool {
+	st.pushPath(ps)
+	defer st.popPath()
+	if c.isIgnored(st) {
+		return false
+	}
+	n := st.differences
+	c.compare(st, v1, v2)
+	return st.differences > n
+}
+
+// isIgnored returns true if the current path matches [Comparator.IgnorePaths].
+func (c *Comparator) isIgnored(st *State) bool {
+	return matchPathPatterns(c.IgnorePaths, st)
+}
+
+// isIgnoredStep returns true if the path of the given child step matches [Comparator.IgnorePaths].
+func (c *Comparator) isIgnoredStep(st *State, ps pathStep) bool {
+	if len(c.IgnorePaths) == 0 {
+		return false
 	}
-	return c.compareKind(st, v1, v2)
+	st.pushPath(ps)
+	defer st.popPath()
+	return c.isIgnored(st)
+}
+
+func matchPathPatterns(ps []*PathPattern, st *State) bool {
+	for _, p := range ps {
+		if st.MatchPathPattern(p) {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *Comparator) checkRecursion(st *State, v1, v2 reflect.Value) bool {
@@ -117,239 +497,711 @@ func (c *Comparator) endRecursion(st *State) {
 	st.Visited = st.Visited[:len(st.Visited)-1]
 }
 
-func (c *Comparator) compareValid(v1, v2 reflect.Value) (Result, bool) {
+func (c *Comparator) compareValid(st *State, v1, v2 reflect.Value) bool {
 	vl1 := v1.IsValid()
 	vl2 := v2.IsValid()
 	if vl1 && vl2 {
-		return nil, false
+		return false
 	}
-	if vl1 == vl2 {
-		return nil, true
+	if vl1 == vl2 || st.reportEqualOnly() {
+		return true
 	}
-	return Result{Difference{
+	st.report(Difference{
+		Kind:    DifferenceOnlyOneValid,
 		Message: msgOnlyOneIsValid,
 		V1:      strconv.FormatBool(vl1),
 		V2:      strconv.FormatBool(vl2),
-	}}, true
+	})
+	return true
 }
 
-func (c *Comparator) compareType(v1, v2 reflect.Value) (Result, bool) {
+func (c *Comparator) compareType(st *State, v1, v2 reflect.Value) bool {
 	t1 := v1.Type()
 	t2 := v2.Type()
 	if t1 == t2 {
-		return nil, false
+		return false
 	}
-	return Result{Difference{
+	if st.reportEqualOnly() {
+		return true
+	}
+	st.report(Difference{
+		Kind:    DifferenceTypeMismatch,
 		Message: msgTypeNotEqual,
 		V1:      t1.String(),
 		V2:      t2.String(),
-	}}, true
+	})
+	return true
 }
 
+func (c *Comparator) compareKind(st *State, v1, v2 reflect.Value) {
+	getKindCompareFunc(v1.Kind())(c, st, v1, v2)
+}
+
+// getKindCompareFunc returns the comparison function for a kind.
+//
 //nolint:gocyclo // Large switch/case is OK.
-func (c *Comparator) compareKind(st *State, v1, v2 reflect.Value) Result {
-	switch v1.Kind() { //nolint:exhaustive // All kinds are handled, Invalid should not happen.
+func getKindCompareFunc(kind reflect.Kind) func(c *Comparator, st *State, v1, v2 reflect.Value) {
+	switch kind { //nolint:exhaustive // All kinds are handled, Invalid should not happen.
 	case reflect.Bool:
-		return c.compareBool(v1, v2)
+		return (*Comparator).compareBool
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return c.compareInt(v1, v2)
+		return (*Comparator).compareInt
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		return c.compareUint(v1, v2)
+		return (*Comparator).compareUint
 	case reflect.Float32, reflect.Float64:
-		return c.compareFloat(v1, v2)
+		return (*Comparator).compareFloat
 	case reflect.Complex64, reflect.Complex128:
-		return c.compareComplex(v1, v2)
+		return (*Comparator).compareComplex
 	case reflect.String:
-		return c.compareString(v1, v2)
+		return (*Comparator).compareString
 	case reflect.Array:
-		return c.compareArray(st, v1, v2)
+		return (*Comparator).compareArray
 	case reflect.Slice:
-		return c.compareSlice(st, v1, v2)
+		return (*Comparator).compareSlice
 	case reflect.Interface:
-		return c.compareInterface(st, v1, v2)
+		return (*Comparator).compareInterface
 	case reflect.Pointer:
-		return c.comparePointer(st, v1, v2)
+		return (*Comparator).comparePointer
 	case reflect.Struct:
-		return c.compareStruct(st, v1, v2)
+		return (*Comparator).compareStruct
 	case reflect.Map:
-		return c.compareMap(st, v1, v2)
+		return (*Comparator).compareMap
 	case reflect.UnsafePointer:
-		return c.compareUnsafePointer(v1, v2)
+		return (*Comparator).compareUnsafePointer
 	case reflect.Chan:
-		return c.compareChan(v1, v2)
+		return (*Comparator).compareChan
 	case reflect.Func:
-		return c.compareFunc(v1, v2)
+		return (*Comparator).compareFunc
 	}
-	return nil
+	return compareNothing
 }
 
-func (c *Comparator) compareBool(v1, v2 reflect.Value) Result {
+func compareNothing(c *Comparator, st *State, v1, v2 reflect.Value) {}
+
+func (c *Comparator) compareBool(st *State, v1, v2 reflect.Value) {
 	b1 := v1.Bool()
 	b2 := v2.Bool()
-	if b1 == b2 {
-		return nil
+	if b1 == b2 || st.reportEqualOnly() {
+		return
 	}
-	return Result{Difference{
+	st.report(Difference{
+		Kind:    DifferenceValueMismatch,
 		Message: msgBoolNotEqual,
 		V1:      strconv.FormatBool(b1),
 		V2:      strconv.FormatBool(b2),
-	}}
+	})
 }
 
-func (c *Comparator) compareInt(v1, v2 reflect.Value) Result {
+func (c *Comparator) compareInt(st *State, v1, v2 reflect.Value) {
 	i1 := v1.Int()
 	i2 := v2.Int()
-	if i1 == i2 {
-		return nil
+	if i1 == i2 || st.reportEqualOnly() {
+		return
 	}
-	return Result{Difference{
+	st.report(Difference{
+		Kind:    DifferenceValueMismatch,
 		Message: msgIntNotEqual,
 		V1:      strconv.FormatInt(i1, 10),
 		V2:      strconv.FormatInt(i2, 10),
-	}}
+	})
 }
 
-func (c *Comparator) compareUint(v1, v2 reflect.Value) Result {
+func (c *Comparator) compareUint(st *State, v1, v2 reflect.Value) {
 	u1 := v1.Uint()
 	u2 := v2.Uint()
-	if u1 == u2 {
-		return nil
+	if u1 == u2 || st.reportEqualOnly() {
+		return
 	}
-	return Result{Difference{
+	st.report(Difference{
+		Kind:    DifferenceValueMismatch,
 		Message: msgUintNotEqual,
 		V1:      strconv.FormatUint(u1, 10),
 		V2:      strconv.FormatUint(u2, 10),
-	}}
+	})
 }
 
-func (c *Comparator) compareFloat(v1, v2 reflect.Value) Result {
+func (c *Comparator) compareFloat(st *State, v1, v2 reflect.Value) {
 	f1 := v1.Float()
 	f2 := v2.Float()
 	if f1 == f2 {
-		return nil
+		return
 	}
 	bitSize := v1.Type().Bits()
-	return Result{Difference{
-		Message: msgFloatNotEqual,
+	ft := c.getFloatTolerance(st)
+	if ft.equal(f1, f2, bitSize) || st.reportEqualOnly() {
+		return
+	}
+	msg := msgFloatNotEqual
+	if !ft.isZero() {
+		msg = fmt.Sprintf(msgFloatNotEqualTolerance, strconv.FormatFloat(math.Abs(f1-f2), 'g', -1, bitSize), ft)
+	}
+	st.report(Difference{
+		Kind:    DifferenceValueMismatch,
+		Message: msg,
 		V1:      strconv.FormatFloat(f1, 'g', -1, bitSize),
 		V2:      strconv.FormatFloat(f2, 'g', -1, bitSize),
-	}}
+	})
 }
 
-func (c *Comparator) compareComplex(v1, v2 reflect.Value) Result {
+func (c *Comparator) compareComplex(st *State, v1, v2 reflect.Value) {
 	c1 := v1.Complex()
 	c2 := v2.Complex()
 	if c1 == c2 {
-		return nil
+		return
 	}
 	bitSize := v1.Type().Bits()
-	return Result{Difference{
-		Message: msgComplexNotEqual,
+	ft := c.getFloatTolerance(st)
+	if ft.equal(real(c1), real(c2), bitSize/2) && ft.equal(imag(c1), imag(c2), bitSize/2) || st.reportEqualOnly() {
+		return
+	}
+	msg := msgComplexNotEqual
+	if !ft.isZero() {
+		delta := complex(math.Abs(real(c1)-real(c2)), math.Abs(imag(c1)-imag(c2)))
+		msg = fmt.Sprintf(msgComplexNotEqualTolerance, strconv.FormatComplex(delta, 'g', -1, bitSize), ft)
+	}
+	st.report(Difference{
+		Kind:    DifferenceValueMismatch,
+		Message: msg,
 		V1:      strconv.FormatComplex(c1, 'g', -1, bitSize),
 		V2:      strconv.FormatComplex(c2, 'g', -1, bitSize),
-	}}
+	})
+}
+
+// getFloatTolerance returns [Comparator.FloatTolerance], overridden by the "approx" struct tag option.
+func (c *Comparator) getFloatTolerance(st *State) FloatTolerance {
+	ft := c.FloatTolerance
+	if st.fieldOptions.approx > 0 {
+		ft.Abs = st.fieldOptions.approx
+	}
+	return ft
 }
 
-func (c *Comparator) compareString(v1, v2 reflect.Value) Result {
+func (c *Comparator) compareString(st *State, v1, v2 reflect.Value) {
 	s1 := v1.String()
 	s2 := v2.String()
-	if s1 == s2 {
-		return nil
+	if s1 == s2 || (st.fieldOptions.noCase && strings.EqualFold(s1, s2)) || st.reportEqualOnly() {
+		return
 	}
-	return Result{Difference{
-		Message: msgStringNotEqual,
-		V1:      strconv.Quote(s1),
-		V2:      strconv.Quote(s2),
-	}}
+	d := c.getStringDifference(s1, s2)
+	if c.StringDiff {
+		d.Diff = c.getStringDiff(st, s1, s2)
+	}
+	st.report(d)
+}
+
+// getStringDifference returns the [Difference] of 2 strings.
+//
+// If a string is longer than [Comparator.StringMaxLength],
+// the message contains the offset of the first difference,
+// and the values are truncated around it.
+func (c *Comparator) getStringDifference(s1, s2 string) Difference {
+	if c.StringMaxLength <= 0 || len(s1) <= c.StringMaxLength && len(s2) <= c.StringMaxLength {
+		return Difference{
+			Kind:    DifferenceValueMismatch,
+			Message: msgStringNotEqual,
+			V1:      strconv.Quote(s1),
+			V2:      strconv.Quote(s2),
+		}
+	}
+	offset := firstStringDifference(s1, s2)
+	return Difference{
+		Kind:    DifferenceValueMismatch,
+		Message: fmt.Sprintf(msgStringNotEqualOffset, offset, utf8.RuneCountInString(s1[:offset])),
+		V1:      quoteStringWindow(s1, offset, c.StringMaxLength),
+		V2:      quoteStringWindow(s2, offset, c.StringMaxLength),
+	}
+}
+
+// getStringDiff returns the unified diff of 2 strings, or an empty string if they are not multi-line.
+func (c *Comparator) getStringDiff(st *State, s1, s2 string) string {
+	if !strings.Contains(s1, "\n") && !strings.Contains(s2, "\n") {
+		return ""
+	}
+	eq := equalString
+	if st.fieldOptions.noCase {
+		eq = strings.EqualFold
+	}
+	diff, _ := unifiedDiff(s1, s2, c.StringDiffContext, eq)
+	return diff
 }
 
-func (c *Comparator) compareArray(st *State, v1, v2 reflect.Value) Result {
-	var r Result
+func equalString(s1, s2 string) bool {
+	return s1 == s2
+}
+
+func (c *Comparator) compareArray(st *State, v1, v2 reflect.Value) {
+	if c.isMemEqual(v1, v2) {
+		return
+	}
+	if sk, ok := c.getSliceKey(v1, v2); ok {
+		c.compareKeyed(st, v1, v2, sk)
+		return
+	}
+	if c.isUnordered(st, v1.Type()) {
+		c.compareUnordered(st, v1, v2)
+		return
+	}
+	c.compareIndexes(st, v1, v2)
+}
+
+// compareIndexes compares the elements of 2 slices or arrays index by index.
+func (c *Comparator) compareIndexes(st *State, v1, v2 reflect.Value) {
 	diffCount := 0
-	for i, n := 0, v1.Len(); i < n; i++ {
-		ri := c.compareArrayIndex(st, v1, v2, i)
-		r = append(r, ri...)
-		if len(ri) > 0 {
+	for i, n := 0, v1.Len(); i < n && !st.stopped; i++ {
+		if c.compareChild(st, pathStep{index: i}, v1.Index(i), v2.Index(i)) {
 			diffCount++
 			if diffCount >= c.SliceMaxDifferences && c.SliceMaxDifferences > 0 {
+				c.reportMaxDifferences(st, msgSliceMaxDifferencesReached, n-i-1)
 				break
 			}
 		}
 	}
-	return r
 }
 
-func (c *Comparator) compareArrayIndex(st *State, v1, v2 reflect.Value, i int) Result {
-	r := c.compare(st, v1.Index(i), v2.Index(i))
-	if len(r) == 0 {
-		return nil
+// reportMaxDifferences reports that the comparison was stopped for the current slice or map.
+// Nothing is reported if all items were compared.
+func (c *Comparator) reportMaxDifferences(st *State, msg string, notCompared int) {
+	if notCompared <= 0 || st.reportEqualOnly() {
+		return
 	}
-	r.pathAppend(PathElem{
-		Index: new(i),
+	st.report(Difference{
+		Kind:    DifferenceTruncated,
+		Message: fmt.Sprintf(msg, notCompared),
 	})
-	return r
 }
 
-func (c *Comparator) compareSlice(st *State, v1, v2 reflect.Value) Result {
-	if r, stop := c.compareNilLenPointer(v1, v2); stop {
-		return r
+func (c *Comparator) compareSlice(st *State, v1, v2 reflect.Value) {
+	if sk, ok := c.getSliceKey(v1, v2); ok {
+		c.compareSliceKeyed(st, v1, v2, sk)
+		return
+	}
+	if c.isUnordered(st, v1.Type()) {
+		c.compareSliceUnordered(st, v1, v2)
+		return
+	}
+	if c.SliceEditScript && !st.equal {
+		c.compareSliceEditScript(st, v1, v2)
+		return
+	}
+	if c.compareNilLenPointer(st, v1, v2) {
+		return
 	}
 	if c.checkRecursion(st, v1, v2) {
-		return nil
+		return
 	}
 	defer c.endRecursion(st)
-	return c.compareArray(st, v1, v2)
+	c.compareIndexes(st, v1, v2)
 }
 
-func (c *Comparator) compareInterface(st *State, v1, v2 reflect.Value) Result {
-	if r, stop := c.compareNil(v1, v2); stop {
-		return r
+// getSliceKey returns the [SliceKey] for the element type of 2 slices or arrays.
+// The values that can't be converted (unexported struct fields) are not compared by key.
+func (c *Comparator) getSliceKey(v1, v2 reflect.Value) (SliceKey, bool) {
+	sk, ok := c.SliceKeys[v1.Type().Elem()]
+	if !ok || !v1.CanInterface() || !v2.CanInterface() {
+		return SliceKey{}, false
 	}
-	return c.compare(st, v1.Elem(), v2.Elem())
+	return sk, true
 }
 
-func (c *Comparator) comparePointer(st *State, v1, v2 reflect.Value) Result {
-	if v1.Pointer() == v2.Pointer() {
-		return nil
+func (c *Comparator) compareSliceKeyed(st *State, v1, v2 reflect.Value, sk SliceKey) {
+	if c.compareNilEmpty(st, v1, v2) {
+		return
+	}
+	if v1.Len() == 0 && v2.Len() == 0 || v1.Len() == v2.Len() && v1.Pointer() == v2.Pointer() {
+		return
 	}
 	if c.checkRecursion(st, v1, v2) {
-		return nil
+		return
 	}
 	defer c.endRecursion(st)
-	return c.compare(st, v1.Elem(), v2.Elem())
+	c.compareKeyed(st, v1, v2, sk)
 }
 
-func (c *Comparator) compareStruct(st *State, v1, v2 reflect.Value) Result {
-	var r Result
-	t := v1.Type()
-	for i, n := 0, t.NumField(); i < n; i++ {
-		r = append(r, c.compareStructField(st, v1, v2, i)...)
+// compareKeyed compares 2 slices or arrays by key.
+//
+// The elements with the same key are compared.
+// The keys defined in only one of the values are reported.
+func (c *Comparator) compareKeyed(st *State, v1, v2 reflect.Value, sk SliceKey) {
+	n2 := v2.Len()
+	indexes2 := make(map[any][]int, n2)
+	for j := range n2 {
+		k := sk.Func(v2.Index(j))
+		indexes2[k] = append(indexes2[k], j)
 	}
-	return r
+	diffCount := 0
+	if c.compareKeyedMatches(st, v1, v2, sk, indexes2, &diffCount) {
+		return
+	}
+	c.reportKeyedNotMatched(st, v1, v2, sk, indexes2, &diffCount)
 }
 
-func (c *Comparator) compareStructField(st *State, v1, v2 reflect.Value, i int) Result {
-	r := c.compare(st, v1.Field(i), v2.Field(i))
-	if len(r) == 0 {
-		return nil
+// compareKeyedMatches compares the elements of v1 with the elements of v2 with the same key.
+// It returns true if the comparison of the slice must stop.
+func (c *Comparator) compareKeyedMatches(st *State, v1, v2 reflect.Value, sk SliceKey, indexes2 map[any][]int, diffCount *int) bool {
+	for i, n1 := 0, v1.Len(); i < n1 && !st.stopped; i++ {
+		e1 := v1.Index(i)
+		k := sk.Func(e1)
+		ps := pathStep{mapKey: reflect.ValueOf(k), keyName: sk.Name}
+		var reported bool
+		if js := indexes2[k]; len(js) > 0 {
+			indexes2[k] = js[1:]
+			reported = c.compareChild(st, ps, e1, v2.Index(js[0]))
+		} else {
+			reported = c.reportSliceKeyNotDefined(st, ps, true, false)
+		}
+		if reported && c.incSliceDifferences(diffCount) {
+			c.reportMaxDifferences(st, msgSliceMaxDifferencesReached, countKeyedNotCompared(v1, i+1, sk, indexes2))
+			return true
+		}
+	}
+	return st.stopped
+}
+
+// reportKeyedNotMatched reports the elements of v2 without match in v1.
+func (c *Comparator) reportKeyedNotMatched(st *State, v1, v2 reflect.Value, sk SliceKey, indexes2 map[any][]int, diffCount *int) {
+	for j, n2 := 0, v2.Len(); j < n2 && !st.stopped; j++ {
+		k := sk.Func(v2.Index(j))
+		js := indexes2[k]
+		if len(js) == 0 || js[0] != j {
+			continue
+		}
+		indexes2[k] = js[1:]
+		if c.reportSliceKeyNotDefined(st, pathStep{mapKey: reflect.ValueOf(k), keyName: sk.Name}, false, true) && c.incSliceDifferences(diffCount) {
+			c.reportMaxDifferences(st, msgSliceMaxDifferencesReached, countKeyedNotCompared(v1, v1.Len(), sk, indexes2))
+			return
+		}
 	}
-	f := v1.Type().Field(i).Name
-	r.pathAppend(PathElem{
-		Struct: new(f),
+}
+
+// incSliceDifferences increments the number of different items of the current slice.
+// It returns true if [Comparator.SliceMaxDifferences] is reached.
+func (c *Comparator) incSliceDifferences(diffCount *int) bool {
+	*diffCount++
+	return *diffCount >= c.SliceMaxDifferences && c.SliceMaxDifferences > 0
+}
+
+// countKeyedNotCompared returns the number of items not compared by [Comparator.compareKeyed],
+// starting at the element i of v1.
+//
+// It modifies indexes2.
+func countKeyedNotCompared(v1 reflect.Value, i int, sk SliceKey, indexes2 map[any][]int) int {
+	n := 0
+	for ; i < v1.Len(); i++ {
+		k := sk.Func(v1.Index(i))
+		if js := indexes2[k]; len(js) > 0 {
+			indexes2[k] = js[1:]
+		}
+		n++
+	}
+	for _, js := range indexes2 {
+		n += len(js)
+	}
+	return n
+}
+
+// reportSliceKeyNotDefined reports a slice key defined in only one of the values.
+// It returns true if a difference was reported.
+func (c *Comparator) reportSliceKeyNotDefined(st *State, ps pathStep, defined1, defined2 bool) bool {
+	st.pushPath(ps)
+	defer st.popPath()
+	if c.isIgnored(st) {
+		return false
+	}
+	if st.reportEqualOnly() {
+		return true
+	}
+	st.report(Difference{
+		Kind:    DifferenceKeyMissing,
+		Message: msgSliceKeyNotDefined,
+		V1:      strconv.FormatBool(defined1),
+		V2:      strconv.FormatBool(defined2),
 	})
-	return r
+	return true
+}
+
+// compareSliceEditScript compares 2 slices with an edit script.
+//
+// If the edit script is too expensive to compute, the slices are compared index by index.
+func (c *Comparator) compareSliceEditScript(st *State, v1, v2 reflect.Value) {
+	if c.compareNilEmpty(st, v1, v2) {
+		return
+	}
+	n1 := v1.Len()
+	n2 := v2.Len()
+	if n1 == n2 && (n1 == 0 || v1.Pointer() == v2.Pointer()) {
+		return
+	}
+	if c.checkRecursion(st, v1, v2) {
+		return
+	}
+	defer c.endRecursion(st)
+	// The elements are matched without IgnorePaths, they are applied when the operations are reported.
+	ops, ok := editScript(n1, n2, func(i1, i2 int) bool {
+		return c.equalElement(st, pathStep{index: i1}, v1.Index(i1), v2.Index(i2))
+	}, editScriptMaxCost)
+	if !ok {
+		if n1 != n2 {
+			st.report(Difference{
+				Kind:    DifferenceLengthMismatch,
+				Message: msgLengthNotEqual,
+				V1:      strconv.Itoa(n1),
+				V2:      strconv.Itoa(n2),
+			})
+			return
+		}
+		c.compareIndexes(st, v1, v2)
+		return
+	}
+	edits := pairEdits(ops)
+	diffCount := 0
+	for k, e := range edits {
+		if st.stopped {
+			return
+		}
+		if c.compareSliceEdit(st, v1, v2, e) {
+			diffCount++
+			if diffCount >= c.SliceMaxDifferences && c.SliceMaxDifferences > 0 {
+				c.reportMaxDifferences(st, msgSliceMaxDifferencesReached, len(edits)-k-1)
+				return
+			}
+		}
+	}
+}
+
+// compareSliceEdit compares or reports an operation of an edit script.
+// It returns true if differences were reported.
+func (c *Comparator) compareSliceEdit(st *State, v1, v2 reflect.Value, e editOp) bool {
+	var msg string
+	var ps pathStep
+	switch e.kind {
+	case editReplace:
+		return c.compareSliceReplace(st, v1.Index(e.i1), v2.Index(e.i2), e)
+	case editDelete:
+		msg = msgSliceElementDeleted
+		ps = pathStep{index: e.i1}
+	default:
+		msg = msgSliceElementInserted
+		ps = pathStep{index: e.i2}
+	}
+	st.pushPath(ps)
+	defer st.popPath()
+	if c.isIgnored(st) {
+		return false
+	}
+	st.report(Difference{
+		Kind:    DifferenceElementMissing,
+		Message: msg,
+		V1:      strconv.Itoa(e.i1),
+		V2:      strconv.Itoa(e.i2),
+	})
+	return true
+}
+
+// compareSliceReplace compares 2 elements replaced by an edit script.
+// If the indexes are different, the replacement is reported with the indexes in both slices before the differences of the elements.
+// It returns true if differences were reported.
+func (c *Comparator) compareSliceReplace(st *State, e1, e2 reflect.Value, e editOp) bool {
+	ps := pathStep{index: e.i1}
+	if e.i1 == e.i2 {
+		return c.compareChild(st, ps, e1, e2)
+	}
+	if c.equalChild(st, ps, e1, e2) {
+		return false
+	}
+	st.pushPath(ps)
+	st.report(Difference{
+		Kind:    DifferenceValueMismatch,
+		Message: msgSliceElementReplaced,
+		V1:      strconv.Itoa(e.i1),
+		V2:      strconv.Itoa(e.i2),
+	})
+	st.popPath()
+	c.compareChild(st, ps, e1, e2)
+	return true
+}
+
+// isUnordered returns true if the current slice or array must be compared as a multiset.
+func (c *Comparator) isUnordered(st *State, typ reflect.Type) bool {
+	return st.fieldOptions.unordered || c.SliceUnordered || c.UnorderedTypes[typ] || matchPathPatterns(c.UnorderedPaths, st)
+}
+
+func (c *Comparator) compareSliceUnordered(st *State, v1, v2 reflect.Value) {
+	if c.compareNilEmpty(st, v1, v2) {
+		return
+	}
+	if v1.Len() == 0 && v2.Len() == 0 || v1.Len() == v2.Len() && v1.Pointer() == v2.Pointer() {
+		return
+	}
+	if c.checkRecursion(st, v1, v2) {
+		return
+	}
+	defer c.endRecursion(st)
+	c.compareUnordered(st, v1, v2)
+}
+
+// compareUnordered compares 2 slices or arrays as multisets.
+//
+// Each element of v1 is matched with an equal element of v2.
+// The elements without match are reported.
+// The ignored elements of v1 are matched last, so they don't take the match of another element.
+func (c *Comparator) compareUnordered(st *State, v1, v2 reflect.Value) {
+	n1 := v1.Len()
+	matched2 := make([]bool, v2.Len())
+	var notFound []unorderedNotFound
+	hasIgnored1 := false
+	for i := range n1 {
+		if c.isIgnoredStep(st, pathStep{index: i}) {
+			hasIgnored1 = true
+			continue
+		}
+		if c.matchUnordered(st, i, v1, v2, matched2) {
+			continue
+		}
+		if st.equal {
+			if c.reportSliceElementNotFound(st, i, true, false) {
+				return
+			}
+			continue
+		}
+		notFound = append(notFound, unorderedNotFound{index: i, found1: true})
+	}
+	for i := 0; i < n1 && hasIgnored1; i++ {
+		if c.isIgnoredStep(st, pathStep{index: i}) {
+			c.matchUnordered(st, i, v1, v2, matched2)
+		}
+	}
+	for j, m := range matched2 {
+		if !m {
+			notFound = append(notFound, unorderedNotFound{index: j, found2: true})
+		}
+	}
+	diffCount := 0
+	for k, nf := range notFound {
+		if st.stopped {
+			return
+		}
+		if c.reportSliceElementNotFound(st, nf.index, nf.found1, nf.found2) && c.incSliceDifferences(&diffCount) {
+			c.reportMaxDifferences(st, msgSliceMaxDifferencesReached, len(notFound)-k-1)
+			return
+		}
+	}
+}
+
+// matchUnordered matches the element i of v1 with the first equal element of v2 that is not matched yet.
+// It returns true if a match was found.
+func (c *Comparator) matchUnordered(st *State, i int, v1, v2 reflect.Value, matched2 []bool) bool {
+	e1 := v1.Index(i)
+	for j, m := range matched2 {
+		if !m && c.equalElement(st, pathStep{index: i}, e1, v2.Index(j)) {
+			matched2[j] = true
+			return true
+		}
+	}
+	return false
+}
+
+type unorderedNotFound struct {
+	index  int
+	found1 bool
+	found2 bool
+}
+
+// reportSliceElementNotFound reports a slice element found in only one of the values.
+// It returns true if a difference was reported.
+func (c *Comparator) reportSliceElementNotFound(st *State, i int, found1, found2 bool) bool {
+	st.pushPath(pathStep{index: i})
+	defer st.popPath()
+	if c.isIgnored(st) {
+		return false
+	}
+	if st.reportEqualOnly() {
+		return true
+	}
+	st.report(Difference{
+		Kind:    DifferenceElementMissing,
+		Message: msgSliceElementNotFound,
+		V1:      strconv.FormatBool(found1),
+		V2:      strconv.FormatBool(found2),
+	})
+	return true
+}
+
+// equalChild returns true if 2 child values at the given path step are equal.
+//
+// It doesn't report the differences.
+func (c *Comparator) equalChild(st *State, ps pathStep, v1, v2 reflect.Value) bool {
+	equal, stopped, differences := st.equal, st.stopped, st.differences
+	st.equal = true
+	c.compareChild(st, ps, v1, v2)
+	eq := st.differences == differences
+	st.equal, st.stopped, st.differences = equal, stopped, differences
+	return eq
+}
+
+// equalElement returns true if 2 child values at the given path step are equal.
+//
+// Unlike [Comparator.equalChild], [Comparator.IgnorePaths] is not checked for this path step, only for the sub-values.
+// It is used to match elements, the ignored elements are handled by the caller.
+func (c *Comparator) equalElement(st *State, ps pathStep, v1, v2 reflect.Value) bool {
+	equal, stopped, differences := st.equal, st.stopped, st.differences
+	st.equal = true
+	st.pushPath(ps)
+	c.compare(st, v1, v2)
+	st.popPath()
+	eq := st.differences == differences
+	st.equal, st.stopped, st.differences = equal, stopped, differences
+	return eq
+}
+
+func (c *Comparator) compareInterface(st *State, v1, v2 reflect.Value) {
+	if c.isNilZero(v1, v2) || c.compareNil(st, v1, v2) {
+		return
+	}
+	c.compare(st, v1.Elem(), v2.Elem())
+}
+
+func (c *Comparator) comparePointer(st *State, v1, v2 reflect.Value) {
+	if v1.Pointer() == v2.Pointer() || c.isNilZero(v1, v2) {
+		return
+	}
+	if c.checkRecursion(st, v1, v2) {
+		return
+	}
+	defer c.endRecursion(st)
+	c.compare(st, v1.Elem(), v2.Elem())
+}
+
+func (c *Comparator) compareStruct(st *State, v1, v2 reflect.Value) {
+	if c.isMemEqual(v1, v2) {
+		return
+	}
+	t := v1.Type()
+	fo := st.fieldOptions
+	defer func() {
+		st.fieldOptions = fo
+	}()
+	for _, f := range getStructFields(t) {
+		if st.stopped {
+			return
+		}
+		st.fieldOptions = f.options
+		c.compareChild(st, pathStep{structType: t, index: f.index}, v1.Field(f.index), v2.Field(f.index))
+	}
 }
 
 //nolint:gocyclo // TODO improve.
-func (c *Comparator) compareMap(st *State, v1, v2 reflect.Value) Result {
-	if r, stop := c.compareNilLenPointer(v1, v2); stop {
-		return r
+func (c *Comparator) compareMap(st *State, v1, v2 reflect.Value) {
+	// The length is not checked, the missing keys are reported instead, so they can be ignored.
+	if c.compareNilEmpty(st, v1, v2) {
+		return
+	}
+	if v1.Len() == 0 && v2.Len() == 0 || v1.Pointer() == v2.Pointer() {
+		return
 	}
 	if c.checkRecursion(st, v1, v2) {
-		return nil
+		return
 	}
 	defer c.endRecursion(st)
-	var r Result
 	diffCount := 0
 	es1 := reflectutil.GetSortedMap(v1)
 	es2 := reflectutil.GetSortedMap(v2)
@@ -358,7 +1210,7 @@ func (c *Comparator) compareMap(st *State, v1, v2 reflect.Value) Result {
 	cmpFunc := reflectutil.GetCompareFunc(v1.Type().Key())
 	i1 := 0
 	i2 := 0
-	for i1 < len(es1) || i2 < len(es2) {
+	for (i1 < len(es1) || i2 < len(es2)) && !st.stopped {
 		var cm int
 		switch {
 		case i1 >= len(es1):
@@ -370,143 +1222,214 @@ func (c *Comparator) compareMap(st *State, v1, v2 reflect.Value) Result {
 		}
 		switch {
 		case cm < 0:
-			r = append(r, Difference{
-				Path: Path{{
-					Map: new(fmt.Sprint(es1[i1].Key)),
-				}},
-				Message: msgMapKeyNotDefined,
-				V1:      strconv.FormatBool(true),
-				V2:      strconv.FormatBool(false),
-			})
+			if c.reportMapKeyNotDefined(st, es1[i1].Key, true, false) {
+				diffCount++
+			}
 			i1++
-			diffCount++
 		case cm > 0:
-			r = append(r, Difference{
-				Path: Path{{
-					Map: new(fmt.Sprint(es2[i2].Key)),
-				}},
-				Message: msgMapKeyNotDefined,
-				V1:      strconv.FormatBool(false),
-				V2:      strconv.FormatBool(true),
-			})
+			if c.reportMapKeyNotDefined(st, es2[i2].Key, false, true) {
+				diffCount++
+			}
 			i2++
-			diffCount++
 		default:
-			er := c.compare(st, es1[i1].Value, es2[i2].Value)
-			if len(er) > 0 {
-				er.pathAppend(PathElem{
-					Map: new(fmt.Sprint(es1[i1].Key)),
-				})
-				r = append(r, er...)
+			if c.compareChild(st, pathStep{mapKey: es1[i1].Key}, es1[i1].Value, es2[i2].Value) {
 				diffCount++
 			}
 			i1++
 			i2++
 		}
 		if diffCount >= c.MapMaxDifferences && c.MapMaxDifferences > 0 {
+			c.reportMaxDifferences(st, msgMapMaxDifferencesReached, countMapKeys(es1[i1:], es2[i2:], cmpFunc))
 			break
 		}
 	}
-	return r
 }
 
-func (c *Comparator) compareUnsafePointer(v1, v2 reflect.Value) Result {
+// countMapKeys returns the number of distinct keys in 2 sorted lists of map entries.
+func countMapKeys(es1, es2 reflectutil.MapEntries, cmpFunc reflectutil.CompareFunc) int {
+	n := 0
+	i1 := 0
+	i2 := 0
+	for i1 < len(es1) && i2 < len(es2) {
+		cm := cmpFunc(es1[i1].Key, es2[i2].Key)
+		if cm <= 0 {
+			i1++
+		}
+		if cm >= 0 {
+			i2++
+		}
+		n++
+	}
+	return n + len(es1) - i1 + len(es2) - i2
+}
+
+// reportMapKeyNotDefined reports a map key defined in only one of the values.
+// It returns true if a difference was reported.
+func (c *Comparator) reportMapKeyNotDefined(st *State, key reflect.Value, defined1, defined2 bool) bool {
+	st.pushPath(pathStep{mapKey: key})
+	defer st.popPath()
+	if c.isIgnored(st) {
+		return false
+	}
+	if st.reportEqualOnly() {
+		return true
+	}
+	st.report(Difference{
+		Kind:    DifferenceKeyMissing,
+		Message: msgMapKeyNotDefined,
+		V1:      strconv.FormatBool(defined1),
+		V2:      strconv.FormatBool(defined2),
+	})
+	return true
+}
+
+func (c *Comparator) compareUnsafePointer(st *State, v1, v2 reflect.Value) {
 	p1 := uintptr(v1.UnsafePointer())
 	p2 := uintptr(v2.UnsafePointer())
-	if p1 == p2 {
-		return nil
+	if p1 == p2 || st.reportEqualOnly() {
+		return
 	}
-	return Result{Difference{
+	st.report(Difference{
+		Kind:    DifferenceValueMismatch,
 		Message: msgUnsafePointerNotEqual,
 		V1:      uintptrToString(p1),
 		V2:      uintptrToString(p2),
-	}}
+	})
 }
 
 func uintptrToString(p uintptr) string {
 	return "0x" + strconv.FormatUint(uint64(p), 16)
 }
 
-func (c *Comparator) compareChan(v1, v2 reflect.Value) Result {
-	if r, stop := c.compareNil(v1, v2); stop {
-		return r
+func (c *Comparator) compareChan(st *State, v1, v2 reflect.Value) {
+	if c.compareNil(st, v1, v2) {
+		return
 	}
 	if v1.Pointer() == v2.Pointer() {
-		return nil
+		return
 	}
 	cap1 := v1.Cap()
 	cap2 := v2.Cap()
 	if cap1 != cap2 {
-		return Result{Difference{
+		if st.reportEqualOnly() {
+			return
+		}
+		st.report(Difference{
+			Kind:    DifferenceCapacityMismatch,
 			Message: msgCapacityNotEqual,
 			V1:      strconv.Itoa(cap1),
 			V2:      strconv.Itoa(cap2),
-		}}
+		})
+		return
 	}
 	len1 := v1.Len()
 	len2 := v2.Len()
 	if len1 != len2 {
-		return Result{Difference{
+		if st.reportEqualOnly() {
+			return
+		}
+		st.report(Difference{
+			Kind:    DifferenceLengthMismatch,
 			Message: msgLengthNotEqual,
 			V1:      strconv.Itoa(len1),
 			V2:      strconv.Itoa(len2),
-		}}
+		})
 	}
-	return nil
 }
 
-func (c *Comparator) compareFunc(v1, v2 reflect.Value) Result {
-	if r, stop := c.compareNil(v1, v2); stop {
-		return r
+func (c *Comparator) compareFunc(st *State, v1, v2 reflect.Value) {
+	if c.compareNil(st, v1, v2) {
+		return
 	}
 	p1 := uintptr(v1.UnsafePointer())
 	p2 := uintptr(v2.UnsafePointer())
-	if p1 == p2 {
-		return nil
+	if p1 == p2 || st.reportEqualOnly() {
+		return
 	}
-	return Result{Difference{
+	st.report(Difference{
+		Kind:    DifferenceValueMismatch,
 		Message: msgFuncPointerNotEqual,
 		V1:      runtime.FuncForPC(p1).Name(),
 		V2:      runtime.FuncForPC(p2).Name(),
-	}}
+	})
 }
 
-func (c *Comparator) compareNil(v1, v2 reflect.Value) (Result, bool) {
+func (c *Comparator) compareNil(st *State, v1, v2 reflect.Value) bool {
 	nil1 := v1.IsNil()
 	nil2 := v2.IsNil()
 	if nil1 && nil2 {
-		return nil, true
+		return true
 	}
 	if nil1 != nil2 {
-		return Result{Difference{
+		if st.reportEqualOnly() {
+			return true
+		}
+		st.report(Difference{
+			Kind:    DifferenceOnlyOneNil,
 			Message: msgOnlyOneIsNil,
 			V1:      strconv.FormatBool(nil1),
 			V2:      strconv.FormatBool(nil2),
-		}}, true
+		})
+		return true
+	}
+	return false
+}
+
+// isNilZero returns true if [Comparator.NilZeroEqual] is enabled,
+// and one of the pointers or interfaces is nil and the other one points to or contains a zero value.
+func (c *Comparator) isNilZero(v1, v2 reflect.Value) bool {
+	if !c.NilZeroEqual {
+		return false
+	}
+	switch {
+	case v1.IsNil() && !v2.IsNil():
+		return v2.Elem().IsZero()
+	case !v1.IsNil() && v2.IsNil():
+		return v1.Elem().IsZero()
+	}
+	return false
+}
+
+// compareNilEmpty compares the nil-ness of 2 slices or maps.
+// It returns true if the comparison is done.
+//
+// If nil and empty values are equal (see [Comparator.NilEmptyEqual]), only the nil-ness of empty values is ignored.
+func (c *Comparator) compareNilEmpty(st *State, v1, v2 reflect.Value) bool {
+	if c.isNilEmptyEqual(v1.Type()) {
+		return v1.Len() == 0 && v2.Len() == 0
+	}
+	return c.compareNil(st, v1, v2)
+}
+
+func (c *Comparator) isNilEmptyEqual(typ reflect.Type) bool {
+	if eq, ok := c.NilEmptyEqualTypes[typ]; ok {
+		return eq
 	}
-	return nil, false
+	return c.NilEmptyEqual
 }
 
-func (c *Comparator) compareNilLenPointer(v1, v2 reflect.Value) (Result, bool) {
-	if r, stop := c.compareNil(v1, v2); stop {
-		return r, true
+func (c *Comparator) compareNilLenPointer(st *State, v1, v2 reflect.Value) bool {
+	if c.compareNilEmpty(st, v1, v2) {
+		return true
 	}
 	len1 := v1.Len()
 	len2 := v2.Len()
 	if len1 != len2 {
-		return Result{Difference{
+		if st.reportEqualOnly() {
+			return true
+		}
+		st.report(Difference{
+			Kind:    DifferenceLengthMismatch,
 			Message: msgLengthNotEqual,
 			V1:      strconv.Itoa(len1),
 			V2:      strconv.Itoa(len2),
-		}}, true
+		})
+		return true
 	}
 	if len1 == 0 {
-		return nil, true
-	}
-	if v1.Pointer() == v2.Pointer() {
-		return nil, true
+		return true
 	}
-	return nil, false
+	return v1.Pointer() == v2.Pointer()
 }
 
 var statePool = syncutil.Pool[*State]{
@@ -521,11 +1444,189 @@ var statePool = syncutil.Pool[*State]{
 type State struct {
 	Depth   int
 	Visited []Visited
+
+	path           []pathStep
+	pathBase       int
+	fieldOptions   fieldOptions
+	result         Result
+	yield          func(Difference) bool
+	equal          bool
+	stopped        bool
+	differences    int
+	maxDifferences int
 }
 
-func (st *State) reset() {
+func (st *State) reset(c *Comparator) {
 	st.Depth = 0
 	st.Visited = st.Visited[:0]
+	st.path = st.path[:0]
+	st.pathBase = 0
+	st.fieldOptions = fieldOptions{}
+	st.result = nil
+	st.yield = nil
+	st.equal = false
+	st.stopped = false
+	st.differences = 0
+	st.maxDifferences = c.MaxDifferences
+}
+
+// report reports a difference.
+//
+// The current path is prepended to the path of the difference.
+// If the maximum number of differences is exceeded, the comparison is stopped.
+// Nothing is reported once the comparison is stopped.
+func (st *State) report(d Difference) {
+	if st.stopped {
+		return
+	}
+	st.differences++
+	switch {
+	case st.equal:
+		st.stopped = true
+	case st.maxDifferences > 0 && st.differences > st.maxDifferences:
+		st.stopped = true
+		st.emit(Difference{
+			Kind:    DifferenceTruncated,
+			Message: msgMaxDifferencesReached,
+		})
+	default:
+		d.Path = st.appendPath(d.Path)
+		st.emit(d)
+	}
+}
+
+// emit yields the difference if the differences are streamed, otherwise it adds it to the result.
+func (st *State) emit(d Difference) {
+	if st.yield != nil {
+		st.stopped = !st.yield(d)
+		return
+	}
+	st.result = append(st.result, d)
+}
+
+// reportResult reports all differences of a [Result].
+func (st *State) reportResult(r Result) {
+	if st.result == nil && st.yield == nil && !st.equal && (st.maxDifferences <= 0 || len(r) <= st.maxDifferences) {
+		// Reuse the Result in order to avoid an allocation.
+		for i := range r {
+			r[i].Path = st.appendPath(r[i].Path)
+		}
+		st.result = r
+		st.differences += len(r)
+		return
+	}
+	for _, d := range r {
+		if st.stopped {
+			return
+		}
+		st.report(d)
+	}
+}
+
+// reportEqualOnly reports a difference without details if only the equality is checked.
+// It returns true if the difference was reported, in this case the caller must not report the details.
+func (st *State) reportEqualOnly() bool {
+	if !st.equal {
+		return false
+	}
+	st.report(Difference{})
+	return true
+}
+
+// Path returns the current path, from the root of the comparison.
+//
+// A new [Path] is built on each call.
+// Use [State.MatchPathPattern] to check the path without allocation.
+func (st *State) Path() Path {
+	if len(st.path) == 0 {
+		return nil
+	}
+	p := make(Path, 0, len(st.path))
+	for _, ps := range slices.Backward(st.path) {
+		p = append(p, ps.pathElem())
+	}
+	return p
+}
+
+// MatchPathPattern returns true if the current path matches the [PathPattern].
+func (st *State) MatchPathPattern(p *PathPattern) bool {
+	return matchPathPattern(p.elems, pathSteps(st.path), 0)
+}
+
+func (st *State) pushPath(ps pathStep) {
+	st.path = append(st.path, ps)
+}
+
+func (st *State) popPath() {
+	st.path = st.path[:len(st.path)-1]
+}
+
+// appendPath appends the current path to p.
+//
+// The path steps before the base are ignored (see [Comparator.CompareValues]).
+func (st *State) appendPath(p Path) Path {
+	path := st.path[st.pathBase:]
+	if len(path) == 0 {
+		return p
+	}
+	p = slices.Grow(p, len(path))
+	for _, ps := range slices.Backward(path) {
+		p = append(p, ps.pathElem())
+	}
+	return p
+}
+
+// pathStep is an element of the current path in [State].
+//
+// It is converted to a [PathElem] only when a difference is reported.
+type pathStep struct {
+	structType reflect.Type
+	mapKey     reflect.Value
+	keyName    string
+	index      int
+}
+
+// mapKeyString returns the string representation of the map key, or of the slice element key.
+func (ps pathStep) mapKeyString() string {
+	if ps.keyName != "" {
+		return ps.keyName + "=" + fmt.Sprint(ps.mapKey)
+	}
+	return fmt.Sprint(ps.mapKey)
+}
+
+func (ps pathStep) pathElem() PathElem {
+	switch {
+	case ps.structType != nil:
+		return PathElem{
+			Struct: new(ps.structType.Field(ps.index).Name),
+		}
+	case ps.mapKey.IsValid():
+		return PathElem{
+			Map: new(ps.mapKeyString()),
+		}
+	default:
+		return PathElem{
+			Index: new(ps.index),
+		}
+	}
+}
+
+// SliceKey is a key extractor for slice elements.
+//
+// The slices and arrays are compared by key: the order of the elements is ignored,
+// the elements with the same key are compared together,
+// and the keys defined in only one of the values are reported.
+// The path of an element is "[name=key]".
+// If several elements have the same key, they are matched in order.
+// The values that can't be converted (unexported struct fields) are not compared by key.
+//
+// It is registered in [Comparator.SliceKeys], see also [WithSliceKey].
+type SliceKey struct {
+	// Name is the name of the key, used in the path.
+	Name string
+	// Func returns the key of an element.
+	// The key must be comparable.
+	Func func(v reflect.Value) any
 }
 
 // Visited represents a visited pair of values.
@@ -538,33 +1639,90 @@ type Visited struct {
 // If the returned value "stop" is true, the comparison will stop.
 type Func func(c *Comparator, st *State, v1, v2 reflect.Value) (r Result, stop bool)
 
-func (c *Comparator) compareFuncs(st *State, v1, v2 reflect.Value) (Result, bool) {
+func (c *Comparator) compareFuncs(st *State, v1, v2 reflect.Value) bool {
+	if f, ok := c.TypeFuncs[v1.Type()]; ok {
+		if r, stop := f(c, st, v1, v2); stop {
+			st.reportResult(r)
+			return true
+		}
+	}
 	for _, f := range c.Funcs {
 		if r, stop := f(c, st, v1, v2); stop {
-			return r, true
+			st.reportResult(r)
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterType registers a comparison function for the type T in [Comparator.TypeFuncs].
+//
+// The function is called with the values converted to T, and its [Result] is reported.
+// The values that can't be converted (unexported struct fields) are compared with [Comparator.Funcs].
+func RegisterType[T any](c *Comparator, f func(c *Comparator, st *State, v1, v2 T) Result) {
+	if c.TypeFuncs == nil {
+		c.TypeFuncs = make(map[reflect.Type]Func)
+	}
+	c.TypeFuncs[reflect.TypeFor[T]()] = func(c *Comparator, st *State, v1, v2 reflect.Value) (Result, bool) {
+		if !v1.CanInterface() || !v2.CanInterface() {
+			return nil, false
 		}
+		t1, _ := reflect.TypeAssert[T](v1)
+		t2, _ := reflect.TypeAssert[T](v2)
+		return f(c, st, t1, t2), true
 	}
-	return nil, false
 }
 
 var typeByteSlice = reflect.TypeFor[[]byte]()
 
-// NewBytesEqualFunc returns a [Func] that compares byte slices with bytes.Equal().
+// NewBytesEqualFunc returns a [Func] that compares []byte and [N]byte with bytes.Equal().
+//
+// If the bytes are not equal, a single [Difference] is reported.
+// V1 and V2 contain the hexadecimal encoding of the bytes (truncated to [Comparator.StringMaxLength]),
+// and the diff contains an xxd-style dump of the different rows (limited to [Comparator.SliceMaxDifferences]).
+// If [Comparator.BytesDiff] is enabled and the bytes are multi-line text, the diff is line-based.
 func NewBytesEqualFunc() Func {
 	return compareBytesEqual
 }
 
 func compareBytesEqual(c *Comparator, st *State, v1, v2 reflect.Value) (Result, bool) {
-	if v1.Type() != typeByteSlice {
+	if !isBytesType(v1.Type()) {
 		return nil, false
 	}
-	if bytes.Equal(v1.Bytes(), v2.Bytes()) {
+	if v1.Kind() == reflect.Array && v1.Equal(v2) {
+		return nil, true
+	}
+	b1 := getBytes(v1)
+	b2 := getBytes(v2)
+	if bytes.Equal(b1, b2) || st.reportEqualOnly() {
 		return nil, true
 	}
-	// If the []byte are not equal,
-	// we want to continue the comparison,
-	// so we will know which elements are not equal.
-	return nil, false
+	if c.BytesDiff {
+		s1 := string(b1)
+		s2 := string(b2)
+		diff := c.getStringDiff(st, s1, s2)
+		if diff != "" {
+			return Result{{
+				Kind:    DifferenceValueMismatch,
+				Message: msgBytesNotEqual,
+				V1:      strconv.Quote(s1),
+				V2:      strconv.Quote(s2),
+				Diff:    diff,
+			}}, true
+		}
+	}
+	msg := msgBytesNotEqual
+	if len(b1) != len(b2) {
+		msg = fmt.Sprintf(msgBytesNotEqualLength, len(b1), len(b2))
+	}
+	offset := firstBytesDifference(b1, b2)
+	return Result{{
+		Kind:    DifferenceValueMismatch,
+		Message: msg,
+		V1:      hexWindow(b1, offset, c.StringMaxLength),
+		V2:      hexWindow(b2, offset, c.StringMaxLength),
+		Diff:    hexDumpDiff(b1, b2, c.SliceMaxDifferences),
+	}}, true
 }
 
 var typeReflectValue = reflect.TypeFor[reflect.Value]()
@@ -585,7 +1743,8 @@ func compareReflectValue(c *Comparator, st *State, v1, v2 reflect.Value) (Result
 	}
 	v1, _ = reflect.TypeAssert[reflect.Value](v1)
 	v2, _ = reflect.TypeAssert[reflect.Value](v2)
-	return c.compare(st, v1, v2), true
+	c.compare(st, v1, v2)
+	return nil, true
 }
 
 // NewMethodEqualFunc returns a [Func] that compares with the method .Equal().
@@ -606,37 +1765,46 @@ func compareMethodEqual(c *Comparator, st *State, v1, v2 reflect.Value) (Result,
 		return nil, true
 	}
 	return Result{Difference{
+		Kind:    DifferenceMethodEqualFalse,
 		Message: msgMethodEqualFalse,
 	}}, true
 }
 
 var (
-	equalMethodFuncsLock sync.Mutex
-	equalMethodFuncs     = make(map[reflect.Type]*reflect.Value)
-	typeBool             = reflect.TypeFor[bool]()
+	equalMethodFuncs syncutil.Map[reflect.Type, *reflect.Value]
+	typeBool         = reflect.TypeFor[bool]()
 )
 
 func getMethodEqualFunc(typ reflect.Type) (reflect.Value, bool) {
-	equalMethodFuncsLock.Lock()
-	defer equalMethodFuncsLock.Unlock()
-	fp, ok := equalMethodFuncs[typ]
-	if ok {
-		if fp != nil {
-			return *fp, true
-		}
+	return getMethodFunc(&equalMethodFuncs, typ, "Equal", typeBool)
+}
+
+// getMethodFunc returns the method with the given name and signature func(T, T) out, and caches it.
+//
+// The cache is lock-free, so it can be used concurrently without contention.
+// A nil value in the cache means that the type doesn't have the method.
+func getMethodFunc(cache *syncutil.Map[reflect.Type, *reflect.Value], typ reflect.Type, name string, out reflect.Type) (reflect.Value, bool) {
+	fp, ok := cache.Load(typ)
+	if !ok {
+		fp = lookupMethodFunc(typ, name, out)
+		fp, _ = cache.LoadOrStore(typ, fp)
+	}
+	if fp == nil {
 		return reflect.Value{}, false
 	}
-	equalMethodFuncs[typ] = nil
-	met, ok := typ.MethodByName("Equal")
+	return *fp, true
+}
+
+func lookupMethodFunc(typ reflect.Type, name string, out reflect.Type) *reflect.Value {
+	met, ok := typ.MethodByName(name)
 	if !ok {
-		return reflect.Value{}, false
+		return nil
 	}
 	metTyp := met.Type
-	if metTyp.NumIn() != 2 || metTyp.In(0) != typ || metTyp.In(1) != typ || metTyp.NumOut() != 1 || metTyp.Out(0) != typeBool {
-		return reflect.Value{}, false
+	if metTyp.NumIn() != 2 || metTyp.In(0) != typ || metTyp.In(1) != typ || metTyp.NumOut() != 1 || metTyp.Out(0) != out {
+		return nil
 	}
-	equalMethodFuncs[typ] = &met.Func
-	return met.Func, true
+	return &met.Func
 }
 
 // NewMethodCmpFunc returns a [Func] that compares with the method .Cmp().
@@ -653,46 +1821,72 @@ func compareMethodCmp(c *Comparator, st *State, v1, v2 reflect.Value) (Result, b
 		return nil, false
 	}
 	cmpRes, _ := reflect.TypeAssert[int](f.Call([]reflect.Value{v1, v2})[0])
-	if cmpRes == 0 {
+	if cmpRes == 0 || st.reportEqualOnly() {
 		return nil, true
 	}
 	return Result{Difference{
+		Kind:    DifferenceMethodCmpNotEqual,
 		Message: fmt.Sprintf(msgMethodCmpNotEqual, cmpRes),
 	}}, true
 }
 
 var (
-	cmdMethodFuncsLock sync.Mutex
-	cmdMethodFuncs     = make(map[reflect.Type]*reflect.Value)
-	typeInt            = reflect.TypeFor[int]()
+	cmpMethodFuncs syncutil.Map[reflect.Type, *reflect.Value]
+	typeInt        = reflect.TypeFor[int]()
 )
 
 func getMethodCmpFunc(typ reflect.Type) (reflect.Value, bool) {
-	cmdMethodFuncsLock.Lock()
-	defer cmdMethodFuncsLock.Unlock()
-	fp, ok := cmdMethodFuncs[typ]
-	if ok {
-		if fp != nil {
-			return *fp, true
-		}
-		return reflect.Value{}, false
-	}
-	cmdMethodFuncs[typ] = nil
-	met, ok := typ.MethodByName("Cmp")
+	return getMethodFunc(&cmpMethodFuncs, typ, "Cmp", typeInt)
+}
+
+// NewMethodCompareFunc returns a [Func] that compares with the method .Compare().
+//
+// The method must have the signature func(T) int, like [time.Time.Compare].
+// The sign of the result is reported.
+func NewMethodCompareFunc() Func {
+	return compareMethodCompare
+}
+
+func compareMethodCompare(c *Comparator, st *State, v1, v2 reflect.Value) (Result, bool) {
+	f, ok := getMethodCompareFunc(v1.Type())
 	if !ok {
-		return reflect.Value{}, false
+		return nil, false
 	}
-	metTyp := met.Type
-	if metTyp.NumIn() != 2 || metTyp.In(0) != typ || metTyp.In(1) != typ || metTyp.NumOut() != 1 || metTyp.Out(0) != typeInt {
-		return reflect.Value{}, false
+	if !v1.CanInterface() || !v2.CanInterface() {
+		return nil, false
+	}
+	cmpRes, _ := reflect.TypeAssert[int](f.Call([]reflect.Value{v1, v2})[0])
+	if cmpRes == 0 || st.reportEqualOnly() {
+		return nil, true
 	}
-	cmdMethodFuncs[typ] = &met.Func
-	return met.Func, true
+	return Result{Difference{
+		Kind:    DifferenceMethodCompareNotEqual,
+		Message: fmt.Sprintf(msgMethodCompareNotEqual, cmp.Compare(cmpRes, 0)),
+	}}, true
+}
+
+var compareMethodFuncs syncutil.Map[reflect.Type, *reflect.Value]
+
+func getMethodCompareFunc(typ reflect.Type) (reflect.Value, bool) {
+	return getMethodFunc(&compareMethodFuncs, typ, "Compare", typeInt)
 }
 
 // Result is a list of [Difference].
 type Result []Difference
 
+// PrependPath prepends a path to the path of all differences, and returns the [Result].
+//
+// It modifies the [Result] in place.
+// It allows a [Func] to prefix the differences returned by [Comparator.CompareValues].
+// Like any [Path], p is in reverse order: the first element is the deepest.
+// For example, the path ".A.B" is passed as the elements B then A.
+func (r Result) PrependPath(p Path) Result {
+	for i := range r {
+		r[i].Path = append(r[i].Path, p...)
+	}
+	return r
+}
+
 // Format implements [fmt.Formatter].
 //
 // See [Difference.Format] for the supported verbs and flags.
@@ -713,12 +1907,6 @@ func (r Result) Format(s fmt.State, verb rune) {
 	}
 }
 
-func (r Result) pathAppend(pe PathElem) {
-	for i := range r {
-		r[i].Path = append(r[i].Path, pe)
-	}
-}
-
 var (
 	resultNoneBytes    = []byte("<none>")
 	resultNewLineBytes = []byte("\n")
@@ -726,17 +1914,21 @@ var (
 
 // Difference represents a difference between 2 values.
 type Difference struct {
-	Path    Path   `json:"path,omitempty"`
-	Message string `json:"message,omitempty"`
-	V1      string `json:"v1,omitempty"`
-	V2      string `json:"v2,omitempty"`
+	Path    Path           `json:"path,omitempty"`
+	Kind    DifferenceKind `json:"kind"`
+	Message string         `json:"message,omitempty"`
+	V1      string         `json:"v1,omitempty"`
+	V2      string         `json:"v2,omitempty"`
+	// Diff is an optional diff between V1 and V2:
+	// a line-based unified diff for multi-line values, or a hexadecimal dump for bytes.
+	Diff string `json:"diff,omitempty"`
 }
 
 // Format implements [fmt.Formatter].
 //
 // It only supports the 'v' verb.
 // By default, it shows the path and message.
-// The '+' flag shows values V1 and V2.
+// The '+' flag shows values V1 and V2, or the diff if it is defined.
 func (d Difference) Format(s fmt.State, verb rune) {
 	if verb != 'v' {
 		_, _ = fmt.Fprintf(s, "%%!%c(%T)", verb, d)
@@ -746,7 +1938,12 @@ func (d Difference) Format(s fmt.State, verb rune) {
 	_, _ = unsafeio.WriteString(s, ": ")
 	_, _ = unsafeio.WriteString(s, d.Message)
 	if s.Flag('+') {
-		if d.V1 != "" || d.V2 != "" {
+		if d.Diff != "" {
+			for l := range strings.SplitSeq(d.Diff, "\n") {
+				_, _ = unsafeio.WriteString(s, "\n\t")
+				_, _ = unsafeio.WriteString(s, l)
+			}
+		} else if d.V1 != "" || d.V2 != "" {
 			_, _ = unsafeio.WriteString(s, "\n\tv1=")
 			_, _ = unsafeio.WriteString(s, d.V1)
 			_, _ = unsafeio.WriteString(s, "\n\tv2=")
@@ -767,11 +1964,27 @@ const (
 	msgFloatNotEqual         = "float not equal"
 	msgComplexNotEqual       = "complex not equal"
 	msgStringNotEqual        = "string not equal"
+	msgBytesNotEqual         = "bytes not equal"
 	msgMapKeyNotDefined      = "map key not defined"
+	msgSliceElementNotFound  = "slice element not found"
+	msgSliceKeyNotDefined    = "slice key not defined"
+	msgSliceElementDeleted   = "slice element deleted in v2"
+	msgSliceElementInserted  = "slice element inserted in v2"
+	msgSliceElementReplaced  = "slice element replaced in v2"
 	msgUnsafePointerNotEqual = "unsafe pointer not equal"
 	msgFuncPointerNotEqual   = "func pointer not equal"
 	msgMethodEqualFalse      = "method .Equal() returned false"
 	msgMethodCmpNotEqual     = "method .Cmp() returned %d"
+	msgMethodCompareNotEqual = "method .Compare() returned %d"
+	msgMaxDifferencesReached = "max differences reached"
+	msgMaxDepthReached       = "max depth reached"
+
+	msgSliceMaxDifferencesReached = "slice max differences reached, %d items not compared"
+	msgMapMaxDifferencesReached   = "map max differences reached, %d keys not compared"
+	msgFloatNotEqualTolerance     = "float not equal, delta=%s exceeds tolerance %s"
+	msgComplexNotEqualTolerance   = "complex not equal, delta=%s exceeds tolerance %s"
+	msgBytesNotEqualLength        = "bytes not equal, length %d != %d"
+	msgStringNotEqualOffset       = "string not equal, first difference at byte %d (rune %d)"
 )
 
 // Path represents a field path, which is a list of [PathElem].
@@ -794,6 +2007,8 @@ func (p Path) Format(s fmt.State, verb rune) {
 }
 
 // PathElem is a single element in a [Path].
+//
+// Map contains the map key, or the slice element key formatted as "name=key" (see [SliceKey]).
 type PathElem struct {
 	Struct *string `json:"struct,omitempty"`
 	Map    *string `json:"map,omitempty"`
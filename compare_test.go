@@ -1,11 +1,17 @@
 package compare_test
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"net"
+	"net/netip"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 	"unsafe" //nolint:depguard // Used for unsafe.Pointer comparison.
@@ -161,6 +167,64 @@ var compareTestCases = []testCase{
 		v1:   "a",
 		v2:   "b",
 	},
+	{
+		name: "StringLongNotEqual",
+		v1:   strings.Repeat("a", 5000) + "b" + strings.Repeat("c", 5000),
+		v2:   strings.Repeat("a", 5000) + "B" + strings.Repeat("c", 5000),
+	},
+	{
+		name: "StringLongNotEqualStart",
+		v1:   "abcdefghijklmnopqrstuvwxyz",
+		v2:   "Abcdefghijklmnopqrstuvwxyz",
+		configure: func(c *Comparator) {
+			c.StringMaxLength = 10
+		},
+	},
+	{
+		name: "StringLongNotEqualEnd",
+		v1:   "abcdefghijklmnopqrstuvwxyz",
+		v2:   "abcdefghijklmnopqrstuvwxy",
+		configure: func(c *Comparator) {
+			c.StringMaxLength = 10
+		},
+	},
+	{
+		name: "StringLongNotEqualUnicode",
+		v1:   "ééééééééééééééééé",
+		v2:   "éééééééééééééêééé",
+		configure: func(c *Comparator) {
+			c.StringMaxLength = 9
+		},
+	},
+	{
+		name: "StringMultilineNotEqual",
+		v1:   "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+		v2:   "0\n1\n2\n3\n4\n5\n6\nseven\n8\n9\n10\n11\n",
+	},
+	{
+		name: "StringMultilineDiffDisabled",
+		v1:   "a\nb",
+		v2:   "a\nc",
+		configure: func(c *Comparator) {
+			c.StringDiff = false
+		},
+	},
+	{
+		name: "StringMultilineDiffContext",
+		v1:   "a\nb\nc\nd\ne",
+		v2:   "a\nb\nC\nd\ne",
+		configure: func(c *Comparator) {
+			c.StringDiffContext = 1
+		},
+	},
+	{
+		name: "BytesDiff",
+		v1:   []byte("a\nb\nc"),
+		v2:   []byte("a\nB\nc"),
+		configure: func(c *Comparator) {
+			c.BytesDiff = true
+		},
+	},
 	{
 		name: "ArrayEqual",
 		v1:   [3]int{1, 2, 3},
@@ -233,6 +297,41 @@ var compareTestCases = []testCase{
 			return s
 		}(),
 	},
+	{
+		name: "SliceByteNotEqualLength",
+		v1:   []byte("hello world, this is a test"),
+		v2:   []byte("hello World, this is a test!"),
+	},
+	{
+		name: "SliceByteNotEqualMaxRows",
+		v1:   make([]byte, 1<<10),
+		v2: func() []byte {
+			s := make([]byte, 1<<10)
+			for i := range s {
+				s[i] = byte(i)
+			}
+			return s
+		}(),
+		configure: func(c *Comparator) {
+			c.SliceMaxDifferences = 2
+			c.StringMaxLength = 32
+		},
+	},
+	{
+		name: "ArrayByteEqual",
+		v1:   sha256.Sum256([]byte("a")),
+		v2:   sha256.Sum256([]byte("a")),
+	},
+	{
+		name: "ArrayByteNotEqual",
+		v1:   sha256.Sum256([]byte("a")),
+		v2:   sha256.Sum256([]byte("b")),
+	},
+	{
+		name: "ArrayByteUnexportedNotEqual",
+		v1:   testStructUnexportedHash{hash: sha256.Sum256([]byte("a"))},
+		v2:   testStructUnexportedHash{hash: sha256.Sum256([]byte("b"))},
+	},
 	{
 		name: "SliceNotEqualMaxDifferences",
 		v1: func() []int {
@@ -338,6 +437,591 @@ var compareTestCases = []testCase{
 			unexported: 2,
 		},
 	},
+	{
+		name: "StructTagEqual",
+		v1: testStructTag{
+			Skip:      1,
+			NoCase:    "abc",
+			Approx:    1.0,
+			Complex:   complex(1, 1),
+			Unordered: []string{"a", "b", "b"},
+			Array:     [3]int{1, 2, 3},
+			Nested:    []testStruct{{Exported: 1}},
+		},
+		v2: testStructTag{
+			Skip:      2,
+			NoCase:    "ABC",
+			Approx:    1.0001,
+			Complex:   complex(1.0001, 0.9999),
+			Unordered: []string{"b", "a", "b"},
+			Array:     [3]int{3, 2, 1},
+			Nested:    []testStruct{{Exported: 1}},
+		},
+	},
+	{
+		name: "StructTagNotEqual",
+		v1: testStructTag{
+			NoCase:    "abc",
+			Approx:    1.0,
+			Complex:   complex(1, 1),
+			Unordered: []string{"a", "b", "b"},
+			Array:     [3]int{1, 2, 3},
+			Nested:    []testStruct{{Exported: 1}},
+		},
+		v2: testStructTag{
+			NoCase:    "abd",
+			Approx:    1.1,
+			Complex:   complex(1, 1.1),
+			Unordered: []string{"b", "a", "a", "c"},
+			Array:     [3]int{3, 2, 2},
+			Nested:    []testStruct{{Exported: 2}},
+		},
+	},
+	{
+		name: "StructTagUnorderedMaxDifferences",
+		v1: testStructTag{
+			Unordered: []string{"a", "b", "c", "d"},
+		},
+		v2: testStructTag{
+			Unordered: []string{"e", "f"},
+		},
+		configure: func(c *Comparator) {
+			c.SliceMaxDifferences = 3
+		},
+	},
+	{
+		name: "StructTagUnorderedOnlyOneIsNil",
+		v1: testStructTag{
+			Unordered: []string{"a"},
+		},
+		v2: testStructTag{},
+	},
+	{
+		name: "IgnorePaths",
+		v1: testStructIgnore{
+			ID:    1,
+			Users: []testStructIgnoreUser{{ID: 1, Name: "a", UpdatedAt: 1}, {ID: 2, Name: "b", UpdatedAt: 2}},
+			Meta:  map[string]int{"a": 1, "b": 2},
+		},
+		v2: testStructIgnore{
+			ID:    2,
+			Users: []testStructIgnoreUser{{ID: 3, Name: "a", UpdatedAt: 3}, {ID: 4, Name: "c", UpdatedAt: 4}},
+			Meta:  map[string]int{"a": 3, "c": 2},
+		},
+		configure: func(c *Comparator) {
+			WithIgnorePaths(".Users[*].UpdatedAt", ".Meta[*]", "**.ID")(c)
+		},
+	},
+	{
+		name: "IgnorePathsSliceMaxDifferences",
+		v1:   []testStructIgnoreUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 3, Name: "c"}},
+		v2:   []testStructIgnoreUser{{ID: 4, Name: "a"}, {ID: 5, Name: "b"}, {ID: 6, Name: "d"}},
+		configure: func(c *Comparator) {
+			c.SliceMaxDifferences = 1
+			WithIgnorePaths("[*].ID")(c)
+		},
+	},
+	{
+		name: "IgnorePathsUnordered",
+		v1: testStructTag{
+			Nested:    []testStruct{{Exported: 1}},
+			Unordered: []string{"a", "b"},
+		},
+		v2: testStructTag{
+			Nested:    []testStruct{{Exported: 2}},
+			Unordered: []string{"c", "d"},
+		},
+		configure: func(c *Comparator) {
+			WithIgnorePaths(".Nested[0].Exported", ".Unordered[1]")(c)
+		},
+	},
+	{
+		name: "IgnorePathsMapLength",
+		v1: testStructIgnore{
+			Meta: map[string]int{"a": 1},
+		},
+		v2: testStructIgnore{
+			Meta: map[string]int{"a": 1, "b": 2},
+		},
+		configure: func(c *Comparator) {
+			WithIgnorePaths(".Meta[b]")(c)
+		},
+	},
+	{
+		name: "IgnorePathsMapMaxDifferences",
+		v1:   map[string]int{"a": 1, "b": 1, "c": 1},
+		v2:   map[string]int{"b": 2, "c": 2, "d": 1},
+		configure: func(c *Comparator) {
+			c.MapMaxDifferences = 1
+			WithIgnorePaths("[a]")(c)
+		},
+	},
+	{
+		name: "IgnorePathsRoot",
+		v1:   1,
+		v2:   2,
+		configure: func(c *Comparator) {
+			WithIgnorePaths(".")(c)
+		},
+	},
+	{
+		name: "FloatToleranceAbsEqual",
+		v1:   1.0,
+		v2:   1.05,
+		configure: func(c *Comparator) {
+			c.FloatTolerance.Abs = 0.1
+		},
+	},
+	{
+		name: "FloatToleranceAbsNotEqual",
+		v1:   1.0,
+		v2:   1.5,
+		configure: func(c *Comparator) {
+			c.FloatTolerance.Abs = 0.1
+		},
+	},
+	{
+		name: "FloatToleranceRelEqual",
+		v1:   1000.0,
+		v2:   1001.0,
+		configure: func(c *Comparator) {
+			c.FloatTolerance.Rel = 0.01
+		},
+	},
+	{
+		name: "FloatToleranceRelNotEqual",
+		v1:   1.0,
+		v2:   1.5,
+		configure: func(c *Comparator) {
+			c.FloatTolerance.Rel = 0.01
+		},
+	},
+	{
+		name: "FloatToleranceULPEqual",
+		v1:   float32(1.0),
+		v2:   math.Nextafter32(math.Nextafter32(1.0, 2), 2),
+		configure: func(c *Comparator) {
+			c.FloatTolerance.ULP = 2
+		},
+	},
+	{
+		name: "FloatToleranceULPEqualSign",
+		v1:   math.Copysign(0, -1),
+		v2:   math.SmallestNonzeroFloat64,
+		configure: func(c *Comparator) {
+			c.FloatTolerance.ULP = 2
+		},
+	},
+	{
+		name: "FloatToleranceULPNotEqual",
+		v1:   1.0,
+		v2:   math.Nextafter(math.Nextafter(math.Nextafter(1.0, 2), 2), 2),
+		configure: func(c *Comparator) {
+			c.FloatTolerance.ULP = 2
+		},
+	},
+	{
+		name: "FloatNaNNotEqual",
+		v1:   math.NaN(),
+		v2:   math.NaN(),
+	},
+	{
+		name: "FloatToleranceNaNEqual",
+		v1:   math.NaN(),
+		v2:   math.NaN(),
+		configure: func(c *Comparator) {
+			c.FloatTolerance.NaNEqual = true
+		},
+	},
+	{
+		name: "FloatToleranceNaNNotEqual",
+		v1:   math.NaN(),
+		v2:   1.0,
+		configure: func(c *Comparator) {
+			c.FloatTolerance = FloatTolerance{Abs: 1e100, NaNEqual: true}
+		},
+	},
+	{
+		name: "FloatToleranceInfEqual",
+		v1:   math.Inf(1),
+		v2:   math.Inf(1),
+		configure: func(c *Comparator) {
+			c.FloatTolerance.Rel = 1e-9
+		},
+	},
+	{
+		name: "FloatToleranceInfNotEqualFinite",
+		v1:   math.Inf(1),
+		v2:   1.0,
+		configure: func(c *Comparator) {
+			c.FloatTolerance.Rel = 1e-9
+		},
+	},
+	{
+		name: "FloatToleranceInfNotEqualSign",
+		v1:   math.Inf(1),
+		v2:   math.Inf(-1),
+		configure: func(c *Comparator) {
+			c.FloatTolerance.Rel = 1e-9
+		},
+	},
+	{
+		name: "ComplexToleranceEqual",
+		v1:   complex64(complex(1, 1)),
+		v2:   complex64(complex(1.05, 0.95)),
+		configure: func(c *Comparator) {
+			c.FloatTolerance.Abs = 0.1
+		},
+	},
+	{
+		name: "ComplexToleranceNotEqual",
+		v1:   complex(1, 1),
+		v2:   complex(1.05, 1.5),
+		configure: func(c *Comparator) {
+			c.FloatTolerance = FloatTolerance{Abs: 0.1, Rel: 0.01, ULP: 4, NaNEqual: true}
+		},
+	},
+	{
+		name: "SliceUnorderedEqual",
+		v1:   []string{"a", "b", "b", "c"},
+		v2:   []string{"c", "b", "a", "b"},
+		configure: func(c *Comparator) {
+			c.SliceUnordered = true
+		},
+	},
+	{
+		name: "SliceUnorderedNotEqual",
+		v1:   []string{"a", "b", "b", "c"},
+		v2:   []string{"c", "b", "a", "a", "d"},
+		configure: func(c *Comparator) {
+			c.SliceUnordered = true
+		},
+	},
+	{
+		name: "SliceUnorderedNested",
+		v1:   [][]int{{1, 2}, {3, 4}},
+		v2:   [][]int{{4, 3}, {2, 1}},
+		configure: func(c *Comparator) {
+			c.SliceUnordered = true
+		},
+	},
+	{
+		name: "SliceUnorderedType",
+		v1:   [][]int{{1, 2}, {3, 4}},
+		v2:   [][]int{{2, 1}, {4, 3}},
+		configure: func(c *Comparator) {
+			WithUnorderedTypes(reflect.TypeFor[[]int]())(c)
+		},
+	},
+	{
+		name: "SliceUnorderedPath",
+		v1: testStructIgnore{
+			Users: []testStructIgnoreUser{{ID: 1}, {ID: 2}, {ID: 3}},
+			Meta:  map[string]int{"a": 1},
+		},
+		v2: testStructIgnore{
+			Users: []testStructIgnoreUser{{ID: 3}, {ID: 1}, {ID: 4}},
+			Meta:  map[string]int{"a": 1},
+		},
+		configure: func(c *Comparator) {
+			WithUnorderedPaths(".Users")(c)
+		},
+	},
+	{
+		name: "SliceUnorderedIgnorePath",
+		v1:   []string{"a", "b"},
+		v2:   []string{"a"},
+		configure: func(c *Comparator) {
+			c.SliceUnordered = true
+			WithIgnorePaths("[1]")(c)
+		},
+	},
+	{
+		name: "SliceUnorderedIgnorePathPermutation",
+		v1:   []int{1, 2, 3},
+		v2:   []int{2, 3, 1},
+		configure: func(c *Comparator) {
+			c.SliceUnordered = true
+			WithIgnorePaths("[0]")(c)
+		},
+	},
+	{
+		name: "SliceUnorderedIgnorePathMaxDifferences",
+		v1:   []string{"a"},
+		v2:   []string{"a", "b", "c"},
+		configure: func(c *Comparator) {
+			c.SliceUnordered = true
+			c.SliceMaxDifferences = 1
+			WithIgnorePaths("[1]")(c)
+		},
+	},
+	{
+		name: "SliceEditScriptEqual",
+		v1:   []int{1, 2, 3},
+		v2:   []int{1, 2, 3},
+		configure: func(c *Comparator) {
+			c.SliceEditScript = true
+		},
+	},
+	{
+		name: "SliceEditScriptInsertHead",
+		v1:   testSliceRange(1, 200),
+		v2:   testSliceRange(0, 200),
+		configure: func(c *Comparator) {
+			c.SliceEditScript = true
+		},
+	},
+	{
+		name: "SliceEditScriptDeleteInsertReplace",
+		v1:   [][]int{{1}, {2}, {3}, {4, 5}, {6}},
+		v2:   [][]int{{1}, {3}, {4, 6}, {6}, {7}},
+		configure: func(c *Comparator) {
+			c.SliceEditScript = true
+		},
+	},
+	{
+		name: "SliceEditScriptIgnorePath",
+		v1:   []int{1, 2, 3},
+		v2:   []int{0, 1, 2, 3, 9},
+		configure: func(c *Comparator) {
+			c.SliceEditScript = true
+			WithIgnorePaths("[0]")(c)
+		},
+	},
+	{
+		name: "SliceEditScriptMaxDifferences",
+		v1:   testSliceRange(0, 10),
+		v2:   testSliceRange(20, 30),
+		configure: func(c *Comparator) {
+			c.SliceEditScript = true
+			c.SliceMaxDifferences = 3
+		},
+	},
+	{
+		name: "SliceKeyEqual",
+		v1:   []testStructIgnoreUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}},
+		v2:   []testStructIgnoreUser{{ID: 2, Name: "b"}, {ID: 1, Name: "a"}},
+		configure: func(c *Comparator) {
+			WithSliceKey("id", func(u testStructIgnoreUser) int { return u.ID })(c)
+		},
+	},
+	{
+		name: "SliceKeyNotEqual",
+		v1:   []testStructIgnoreUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 42, Name: "c"}},
+		v2:   []testStructIgnoreUser{{ID: 3, Name: "d"}, {ID: 42, Name: "e"}, {ID: 1, Name: "a"}},
+		configure: func(c *Comparator) {
+			WithSliceKey("id", func(u testStructIgnoreUser) int { return u.ID })(c)
+		},
+	},
+	{
+		name: "SliceKeyArray",
+		v1:   [2]testStructIgnoreUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}},
+		v2:   [2]testStructIgnoreUser{{ID: 2, Name: "c"}, {ID: 1, Name: "a"}},
+		configure: func(c *Comparator) {
+			WithSliceKey("id", func(u testStructIgnoreUser) int { return u.ID })(c)
+		},
+	},
+	{
+		name: "SliceKeyIgnorePath",
+		v1:   []testStructIgnoreUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}},
+		v2:   []testStructIgnoreUser{{ID: 1, Name: "b"}, {ID: 2, Name: "c"}},
+		configure: func(c *Comparator) {
+			WithSliceKey("id", func(u testStructIgnoreUser) int { return u.ID })(c)
+			WithIgnorePaths("[id=1]")(c)
+		},
+	},
+	{
+		name: "SliceKeyMaxDifferences",
+		v1:   []testStructIgnoreUser{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}},
+		v2:   []testStructIgnoreUser{{ID: 5}, {ID: 6}, {ID: 3, Name: "a"}, {ID: 7}},
+		configure: func(c *Comparator) {
+			WithSliceKey("id", func(u testStructIgnoreUser) int { return u.ID })(c)
+			c.SliceMaxDifferences = 2
+		},
+	},
+	{
+		name: "SliceKeyUnexported",
+		v1:   testStructUnexportedUsers{users: []testStructIgnoreUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}},
+		v2:   testStructUnexportedUsers{users: []testStructIgnoreUser{{ID: 2, Name: "b"}, {ID: 1, Name: "a"}}},
+		configure: func(c *Comparator) {
+			WithSliceKey("id", func(u testStructIgnoreUser) int { return u.ID })(c)
+		},
+	},
+	{
+		name: "NilEmptyEqualSlice",
+		v1:   []int(nil),
+		v2:   []int{},
+		configure: func(c *Comparator) {
+			c.NilEmptyEqual = true
+		},
+	},
+	{
+		name: "NilEmptyEqualMap",
+		v1:   map[string]int{},
+		v2:   map[string]int(nil),
+		configure: func(c *Comparator) {
+			c.NilEmptyEqual = true
+		},
+	},
+	{
+		name: "NilEmptyEqualSliceNotEmpty",
+		v1:   []int(nil),
+		v2:   []int{1},
+		configure: func(c *Comparator) {
+			c.NilEmptyEqual = true
+		},
+	},
+	{
+		name: "NilEmptyEqualUnordered",
+		v1:   []int(nil),
+		v2:   []int{},
+		configure: func(c *Comparator) {
+			c.NilEmptyEqual = true
+			c.SliceUnordered = true
+		},
+	},
+	{
+		name: "NilEmptyEqualTypeEnabled",
+		v1:   []int(nil),
+		v2:   []int{},
+		configure: func(c *Comparator) {
+			WithNilEmptyEqualType(reflect.TypeFor[[]int](), true)(c)
+		},
+	},
+	{
+		name: "NilEmptyEqualTypeDisabled",
+		v1:   []int(nil),
+		v2:   []int{},
+		configure: func(c *Comparator) {
+			c.NilEmptyEqual = true
+			WithNilEmptyEqualType(reflect.TypeFor[[]int](), false)(c)
+		},
+	},
+	{
+		name: "NilZeroEqualPointer",
+		v1:   (*testStruct)(nil),
+		v2:   &testStruct{},
+		configure: func(c *Comparator) {
+			c.NilZeroEqual = true
+		},
+	},
+	{
+		name: "NilZeroEqualPointerNotZero",
+		v1:   (*testStruct)(nil),
+		v2:   &testStruct{Exported: 1},
+		configure: func(c *Comparator) {
+			c.NilZeroEqual = true
+		},
+	},
+	{
+		name: "NilZeroEqualInterface",
+		v1:   []any{nil},
+		v2:   []any{0},
+		configure: func(c *Comparator) {
+			c.NilZeroEqual = true
+		},
+	},
+	{
+		name: "CompareValuesEqual",
+		v1:   testOptional{Valid: true, Value: []int{1, 2}},
+		v2:   testOptional{Valid: true, Value: []int{1, 2}},
+		configure: func(c *Comparator) {
+			WithPrependFunc(testCompareOptional)(c)
+		},
+	},
+	{
+		name: "CompareValuesNotEqual",
+		v1:   []testOptional{{Valid: true, Value: []int{1, 2}}},
+		v2:   []testOptional{{Valid: true, Value: []int{1, 3}}},
+		configure: func(c *Comparator) {
+			WithPrependFunc(testCompareOptional)(c)
+		},
+	},
+	{
+		name: "CompareValuesNotEqualValid",
+		v1:   testOptional{Valid: true, Value: 1},
+		v2:   testOptional{Value: 1},
+		configure: func(c *Comparator) {
+			WithPrependFunc(testCompareOptional)(c)
+		},
+	},
+	{
+		name: "CompareValuesMaxDifferences",
+		v1:   testOptional{Valid: true, Value: []int{1, 2, 3}},
+		v2:   testOptional{Valid: true, Value: []int{4, 5, 6}},
+		configure: func(c *Comparator) {
+			WithPrependFunc(testCompareOptional)(c)
+			c.MaxDifferences = 2
+		},
+	},
+	{
+		name: "StatePathPattern",
+		v1: testStructMetrics{
+			Metrics: map[string]float64{"a": 1, "b": 2},
+			Total:   3,
+		},
+		v2: testStructMetrics{
+			Metrics: map[string]float64{"a": 1.05, "b": 2.5},
+			Total:   3.05,
+		},
+		configure: func(c *Comparator) {
+			WithPrependFunc(testCompareMetrics)(c)
+		},
+	},
+	{
+		name: "RegisterTypeEqual",
+		v1:   []testStructIgnoreUser{{ID: 1, Name: "a"}},
+		v2:   []testStructIgnoreUser{{ID: 1, Name: "b"}},
+		configure: func(c *Comparator) {
+			RegisterType(c, testCompareUserID)
+		},
+	},
+	{
+		name: "RegisterTypeNotEqual",
+		v1:   []testStructIgnoreUser{{ID: 1, Name: "a"}},
+		v2:   []testStructIgnoreUser{{ID: 2, Name: "a"}},
+		configure: func(c *Comparator) {
+			RegisterType(c, testCompareUserID)
+		},
+	},
+	{
+		name: "RegisterTypeUnexported",
+		v1:   testStructUnexportedUser{user: testStructIgnoreUser{ID: 1, Name: "a"}},
+		v2:   testStructUnexportedUser{user: testStructIgnoreUser{ID: 1, Name: "b"}},
+		configure: func(c *Comparator) {
+			RegisterType(c, testCompareUserID)
+		},
+	},
+	{
+		name: "MemEqualArray",
+		v1:   [4096]uint32{1: 1},
+		v2:   [4096]uint32{1: 1},
+	},
+	{
+		name: "MemEqualArrayAddressable",
+		v1:   &[4096]uint32{1: 1},
+		v2:   &[4096]uint32{1: 1},
+	},
+	{
+		name: "MemEqualStructAddressable",
+		v1:   []testStructMem{{A: 1, B: [3]uint16{2}, C: true}},
+		v2:   []testStructMem{{A: 1, B: [3]uint16{2}, C: true}},
+	},
+	{
+		name: "MemEqualStructPadding",
+		v1:   []testStructMemPadding{{A: true, B: 1}},
+		v2:   []testStructMemPadding{{A: true, B: 1}},
+	},
+	{
+		name: "MemNotEqualArray",
+		v1:   [4096]uint32{1: 1},
+		v2:   [4096]uint32{2: 1},
+	},
+	{
+		name: "MemNotEqualStructAddressable",
+		v1:   []testStructMem{{A: 1, B: [3]uint16{2}, C: true}},
+		v2:   []testStructMem{{A: 1, B: [3]uint16{3}, C: true}},
+	},
 	{
 		name: "MapEqual",
 		v1: map[string]int{
@@ -429,6 +1113,28 @@ var compareTestCases = []testCase{
 			return m
 		}(),
 	},
+	{
+		name: "MaxDifferences",
+		v1: []map[string]int{
+			{"a": 1, "b": 1},
+			{"a": 1, "b": 1},
+		},
+		v2: []map[string]int{
+			{"a": 2, "b": 2},
+			{"a": 2, "b": 2},
+		},
+		configure: func(c *Comparator) {
+			c.MaxDifferences = 3
+		},
+	},
+	{
+		name: "MaxDifferencesNotExceeded",
+		v1:   []int{1, 2, 3},
+		v2:   []int{4, 5, 6},
+		configure: func(c *Comparator) {
+			c.MaxDifferences = 3
+		},
+	},
 	{
 		name: "UnsafePointerEqual",
 		v1:   unsafe.Pointer(&testInt),
@@ -514,6 +1220,26 @@ var compareTestCases = []testCase{
 		v1:   net.ParseIP("111.111.111.111"),
 		v2:   net.ParseIP("222.222.222.222"),
 	},
+	{
+		name: "NetIPAddrEqual",
+		v1:   netip.MustParseAddr("111.111.111.111"),
+		v2:   netip.MustParseAddr("111.111.111.111"),
+	},
+	{
+		name: "NetIPAddrNotEqual",
+		v1:   netip.MustParseAddr("111.111.111.111"),
+		v2:   netip.MustParseAddr("222.222.222.222"),
+	},
+	{
+		name: "MethodCompareEqual",
+		v1:   testCompareMethod{Value: 1},
+		v2:   testCompareMethod{Value: 1},
+	},
+	{
+		name: "MethodCompareNotEqual",
+		v1:   testCompareMethod{Value: 1},
+		v2:   testCompareMethod{Value: 5},
+	},
 	{
 		name: "MatBigIntEqual",
 		v1:   big.NewInt(1),
@@ -576,6 +1302,14 @@ var compareTestCases = []testCase{
 	},
 }
 
+func testSliceRange(start, end int) []int {
+	s := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		s = append(s, i)
+	}
+	return s
+}
+
 func TestCompare(t *testing.T) {
 	for _, tc := range compareTestCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -608,6 +1342,271 @@ func BenchmarkCompare(b *testing.B) {
 	}
 }
 
+func TestCompareFrozen(t *testing.T) {
+	for _, tc := range compareTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := tc.newComparator()
+			fc := tc.newComparator().Freeze()
+			for range 2 {
+				assert.DeepEqual(t, fc.Compare(tc.v1, tc.v2), c.Compare(tc.v1, tc.v2))
+			}
+		})
+	}
+}
+
+func BenchmarkCompareFrozen(b *testing.B) {
+	for _, tc := range compareTestCases {
+		b.Run(tc.name, func(b *testing.B) {
+			c := tc.newComparator().Freeze()
+			for b.Loop() {
+				c.Compare(tc.v1, tc.v2)
+			}
+		})
+	}
+}
+
+func BenchmarkCompareParallel(b *testing.B) {
+	for _, tc := range compareTestCases {
+		b.Run(tc.name, func(b *testing.B) {
+			c := tc.newComparator()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					c.Compare(tc.v1, tc.v2)
+				}
+			})
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	for _, tc := range compareTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := tc.newComparator()
+			eq := c.Equal(tc.v1, tc.v2)
+			assert.Equal(t, eq, len(c.Compare(tc.v1, tc.v2)) == 0)
+		})
+	}
+}
+
+func TestEqualAllocs(t *testing.T) {
+	for _, tc := range compareTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := tc.newComparator()
+			assertauto.AllocsPerRun(t, 100, func() {
+				c.Equal(tc.v1, tc.v2)
+			})
+		})
+	}
+}
+
+func BenchmarkEqual(b *testing.B) {
+	for _, tc := range compareTestCases {
+		b.Run(tc.name, func(b *testing.B) {
+			c := tc.newComparator()
+			for b.Loop() {
+				c.Equal(tc.v1, tc.v2)
+			}
+		})
+	}
+}
+
+func TestDifferences(t *testing.T) {
+	for _, tc := range compareTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := tc.newComparator()
+			r := Result(slices.Collect(c.Differences(tc.v1, tc.v2)))
+			assert.DeepEqual(t, r, c.Compare(tc.v1, tc.v2))
+		})
+	}
+}
+
+func TestDifferencesStop(t *testing.T) {
+	v1 := []int{1, 2, 3}
+	v2 := []int{4, 5, 6}
+	var r Result
+	for d := range Differences(v1, v2) {
+		r = append(r, d)
+		break
+	}
+	assertauto.Equal(t, r)
+}
+
+func TestDifferencesStopMaxDifferences(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		c      *Comparator
+		v1, v2 any
+	}{
+		{
+			name: "Slice",
+			c:    NewComparator(WithSliceMaxDifferences(1)),
+			v1:   []int{1, 2, 3},
+			v2:   []int{4, 5, 6},
+		},
+		{
+			name: "Map",
+			c:    NewComparator(WithMapMaxDifferences(1)),
+			v1:   map[int]int{1: 1, 2: 2, 3: 3},
+			v2:   map[int]int{1: 4, 2: 5, 3: 6},
+		},
+		{
+			name: "MaxDifferences",
+			c:    NewComparator(WithMaxDifferences(1)),
+			v1:   []int{1, 2, 3},
+			v2:   []int{4, 5, 6},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var r Result
+			for d := range tc.c.Differences(tc.v1, tc.v2) {
+				r = append(r, d)
+				break
+			}
+			assert.SliceLen(t, r, 1)
+		})
+	}
+}
+
+func BenchmarkDifferences(b *testing.B) {
+	for _, tc := range compareTestCases {
+		b.Run(tc.name, func(b *testing.B) {
+			c := tc.newComparator()
+			for b.Loop() {
+				for d := range c.Differences(tc.v1, tc.v2) {
+					_ = d
+				}
+			}
+		})
+	}
+}
+
+func TestCompareT(t *testing.T) {
+	for _, tc := range compareTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := tc.newComparator()
+			r := CompareTWith(c, tc.v1, tc.v2)
+			assert.DeepEqual(t, r, c.Compare(tc.v1, tc.v2))
+		})
+	}
+}
+
+func TestCompareTStruct(t *testing.T) {
+	v1 := testStruct{Exported: 1}
+	v2 := testStruct{Exported: 2}
+	r := CompareT(v1, v2)
+	assertauto.Equal(t, r)
+}
+
+func TestCompareTStructAllocs(t *testing.T) {
+	v1 := testStruct{Exported: 1}
+	v2 := testStruct{Exported: 1}
+	assertauto.AllocsPerRun(t, 100, func() {
+		CompareT(v1, v2)
+	})
+}
+
+func BenchmarkCompareT(b *testing.B) {
+	v1 := testStruct{Exported: 1}
+	v2 := testStruct{Exported: 1}
+	for b.Loop() {
+		CompareT(v1, v2)
+	}
+}
+
+func TestComparatorClone(t *testing.T) {
+	c := NewComparator().Freeze()
+	nc := c.Clone()
+	assert.False(t, nc.IsFrozen())
+	nc.MaxDepth = 1
+	nc.Funcs[0] = nil
+	assert.Equal(t, c.MaxDepth, 0)
+	assert.True(t, c.Funcs[0] != nil)
+	assert.NotPanics(t, func() {
+		c.Compare(1, 1)
+	})
+}
+
+func TestComparatorFreeze(t *testing.T) {
+	c := NewComparator().Freeze()
+	assert.True(t, c.IsFrozen())
+	assert.NotPanics(t, func() {
+		c.Compare(1, 1)
+	})
+	v := reflect.ValueOf(c).Elem()
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		t.Run(f.Name, func(t *testing.T) {
+			c := NewComparator().Freeze()
+			testModifyValue(reflect.ValueOf(c).Elem().Field(i))
+			assert.Panics(t, func() {
+				c.Compare(1, 1)
+			})
+		})
+	}
+}
+
+func TestComparatorFreezeClosureReplaced(t *testing.T) {
+	register := func(c *Comparator, equal bool) {
+		RegisterType(c, func(c *Comparator, st *State, v1, v2 int) Result {
+			if equal {
+				return nil
+			}
+			return Result{{Message: "always different"}}
+		})
+	}
+	c := NewComparator()
+	register(c, true)
+	c.Freeze()
+	// The closure is replaced before the plan of the type is cached.
+	register(c, false)
+	assert.NotPanics(t, func() {
+		assert.SliceEmpty(t, c.Compare(1, 2))
+	})
+	assert.True(t, c.Equal(1, 2))
+}
+
+func testModifyValue(v reflect.Value) {
+	switch v.Kind() { //nolint:exhaustive // Only the kinds used by Comparator are supported.
+	case reflect.Bool:
+		v.SetBool(!v.Bool())
+	case reflect.Int:
+		v.SetInt(v.Int() + 1)
+	case reflect.Uint64:
+		v.SetUint(v.Uint() + 1)
+	case reflect.Float64:
+		v.SetFloat(v.Float() + 1)
+	case reflect.Slice:
+		v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(reflect.Zero(v.Type().Key()), reflect.Zero(v.Type().Elem()))
+	case reflect.Struct:
+		testModifyValue(v.Field(0))
+	default:
+		panic("unsupported kind: " + v.Kind().String())
+	}
+}
+
+func TestDefaultComparatorIsFrozen(t *testing.T) {
+	assert.True(t, DefaultComparator.Load().IsFrozen())
+}
+
+func TestSetDefaultComparator(t *testing.T) {
+	prev := DefaultComparator.Load()
+	t.Run("Set", func(t *testing.T) {
+		c := NewComparator(WithFuncs(testFuncAlwaysEqual))
+		SetDefaultComparator(t, c)
+		assert.Equal(t, DefaultComparator.Load(), c)
+		assert.SliceEmpty(t, Compare(1, 2))
+	})
+	assert.Equal(t, DefaultComparator.Load(), prev)
+}
+
 func TestCompareUnsafePointerNotEqual(t *testing.T) {
 	v1 := unsafe.Pointer(&testInt)
 	v2 := unsafe.Pointer(&testSlice)
@@ -634,6 +1633,129 @@ type testStruct struct {
 	unexported int
 }
 
+type testStructTag struct {
+	Skip      int          `compare:"-"`
+	NoCase    string       `compare:"nocase"`
+	Approx    float64      `compare:"approx=0.001"`
+	Complex   complex128   `compare:"approx=0.001"`
+	Unordered []string     `compare:"unordered"`
+	Array     [3]int       `compare:"unordered"`
+	Nested    []testStruct `compare:"nocase,unknown"`
+}
+
+type testStructIgnore struct {
+	ID    int
+	Users []testStructIgnoreUser
+	Meta  map[string]int
+}
+
+type testOptional struct {
+	Valid bool
+	Value any
+}
+
+// testCompareOptional compares [testOptional] values, and delegates the comparison of the values to the [Comparator].
+func testCompareOptional(c *Comparator, st *State, v1, v2 reflect.Value) (Result, bool) {
+	if v1.Type() != reflect.TypeFor[testOptional]() {
+		return nil, false
+	}
+	valid1 := v1.Field(0).Bool()
+	valid2 := v2.Field(0).Bool()
+	if valid1 != valid2 {
+		return Result{{
+			Message: "optional validity not equal",
+			V1:      strconv.FormatBool(valid1),
+			V2:      strconv.FormatBool(valid2),
+		}}, true
+	}
+	if !valid1 {
+		return nil, true
+	}
+	return c.CompareValues(st, v1.Field(1), v2.Field(1)).PrependPath(Path{{Struct: new("Get()")}}), true
+}
+
+type testStructMetrics struct {
+	Metrics map[string]float64
+	Total   float64
+}
+
+var testMetricsPathPattern = MustParsePathPattern(".Metrics[*]")
+
+// testCompareMetrics compares the floats under .Metrics with a tolerance.
+func testCompareMetrics(c *Comparator, st *State, v1, v2 reflect.Value) (Result, bool) {
+	if v1.Kind() != reflect.Float64 || !st.MatchPathPattern(testMetricsPathPattern) {
+		return nil, false
+	}
+	return nil, math.Abs(v1.Float()-v2.Float()) <= 0.1
+}
+
+func TestStatePath(t *testing.T) {
+	newValue := func() testStructIgnore {
+		return testStructIgnore{
+			Users: []testStructIgnoreUser{{ID: 1}},
+			Meta:  map[string]int{"a": 1},
+		}
+	}
+	var paths []string
+	c := NewComparator(WithPrependFunc(func(c *Comparator, st *State, v1, v2 reflect.Value) (Result, bool) {
+		paths = append(paths, fmt.Sprint(st.Path()))
+		return nil, false
+	}))
+	r := c.Compare(newValue(), newValue())
+	assert.SliceEmpty(t, r)
+	assertauto.Equal(t, paths)
+}
+
+type testStructUnexportedUsers struct {
+	users []testStructIgnoreUser
+}
+
+type testStructUnexportedHash struct {
+	hash [sha256.Size]byte
+}
+
+type testStructUnexportedUser struct {
+	user testStructIgnoreUser
+}
+
+// testCompareUserID compares [testStructIgnoreUser] values by ID only.
+func testCompareUserID(c *Comparator, st *State, v1, v2 testStructIgnoreUser) Result {
+	if v1.ID == v2.ID {
+		return nil
+	}
+	return Result{{
+		Message: "user ID not equal",
+		V1:      strconv.Itoa(v1.ID),
+		V2:      strconv.Itoa(v2.ID),
+	}}
+}
+
+type testCompareMethod struct {
+	Value int
+}
+
+func (v testCompareMethod) Compare(other testCompareMethod) int {
+	return v.Value - other.Value
+}
+
+type testStructMem struct {
+	A int64
+	B [3]uint16
+	C bool
+	D [1]uint8
+}
+
+type testStructMemPadding struct {
+	A bool
+	B int64
+}
+
+type testStructIgnoreUser struct {
+	ID        int
+	Name      string
+	UpdatedAt int
+}
+
 var testResult = Result{
 	Difference{
 		Message: "test1",
@@ -645,6 +1767,12 @@ var testResult = Result{
 		V1:      "a",
 		V2:      "b",
 	},
+	Difference{
+		Message: "test3",
+		V1:      `"a\nb"`,
+		V2:      `"a\nc"`,
+		Diff:    "@@ -1,2 +1,2 @@\n a\n-b\n+c",
+	},
 }
 
 func TestResultFormat(t *testing.T) {
@@ -666,6 +1794,15 @@ func BenchmarkResultFormat(b *testing.B) {
 	}
 }
 
+func TestResultPrependPath(t *testing.T) {
+	r := Result{{
+		Path:    Path{{Struct: new("X")}},
+		Message: "test",
+	}}
+	r = r.PrependPath(Path{{Struct: new("B")}, {Index: new(1)}, {Struct: new("A")}})
+	assert.Equal(t, fmt.Sprint(r[0].Path), ".A[1].B.X")
+}
+
 func TestResultFormatEmpty(t *testing.T) {
 	var r Result
 	s := fmt.Sprintf("%+v", r)
@@ -0,0 +1,238 @@
+package compare
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// editKind is the kind of an [editOp].
+type editKind uint8
+
+const (
+	editEqual editKind = iota
+	editDelete
+	editInsert
+	editReplace
+)
+
+// editOp is an operation of an edit script.
+//
+// i1 is the index in the first sequence, and i2 is the index in the second sequence.
+// For a deletion, i2 is the position in the second sequence.
+// For an insertion, i1 is the position in the first sequence.
+type editOp struct {
+	kind editKind
+	i1   int
+	i2   int
+}
+
+// editScriptMaxCost is the maximum number of deletions and insertions computed by [editScript].
+const editScriptMaxCost = 1000
+
+// editScript returns the shortest edit script that transforms a sequence of length n1 into a sequence of length n2.
+//
+// eq returns true if the elements at the given indexes are equal.
+// It uses the Myers' diff algorithm, after trimming the common prefix and suffix.
+// It returns false if the edit script requires more than maxCost deletions and insertions.
+func editScript(n1, n2 int, eq func(i1, i2 int) bool, maxCost int) ([]editOp, bool) {
+	prefix := 0
+	for prefix < n1 && prefix < n2 && eq(prefix, prefix) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < n1-prefix && suffix < n2-prefix && eq(n1-1-suffix, n2-1-suffix) {
+		suffix++
+	}
+	m1 := n1 - prefix - suffix
+	m2 := n2 - prefix - suffix
+	ops := make([]editOp, 0, max(n1, n2))
+	for i := range prefix {
+		ops = append(ops, editOp{kind: editEqual, i1: i, i2: i})
+	}
+	ops, ok := myers(ops, m1, m2, prefix, eq, maxCost)
+	if !ok {
+		return nil, false
+	}
+	for i := range suffix {
+		ops = append(ops, editOp{kind: editEqual, i1: n1 - suffix + i, i2: n2 - suffix + i})
+	}
+	return ops, true
+}
+
+// myers appends the edit script of 2 sub-sequences of length n1 and n2, starting at offset, to ops.
+func myers(ops []editOp, n1, n2 int, offset int, eq func(i1, i2 int) bool, maxCost int) ([]editOp, bool) {
+	vOff := n1 + n2 + 1
+	v := make([]int, 2*vOff+1)
+	var trace [][]int
+	for d := 0; d <= n1+n2; d++ {
+		if maxCost > 0 && d > maxCost {
+			return nil, false
+		}
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[vOff+k-1] < v[vOff+k+1] {
+				x = v[vOff+k+1]
+			} else {
+				x = v[vOff+k-1] + 1
+			}
+			y := x - k
+			for x < n1 && y < n2 && eq(offset+x, offset+y) {
+				x++
+				y++
+			}
+			v[vOff+k] = x
+			if x >= n1 && y >= n2 {
+				return myersBacktrack(ops, trace, n1, n2, offset), true
+			}
+		}
+		// Only the diagonals [-d, d] are used by the next step.
+		trace = append(trace, slices.Clone(v[vOff-d:vOff+d+1]))
+	}
+	return myersBacktrack(ops, trace, n1, n2, offset), true
+}
+
+// myersBacktrack appends the edit script found by [myers] to ops.
+//
+// trace[d] contains the furthest reaching x for the diagonals [-d, d] after the step d.
+func myersBacktrack(ops []editOp, trace [][]int, n1, n2 int, offset int) []editOp {
+	start := len(ops)
+	x, y := n1, n2
+	for d := len(trace); d > 0; d-- {
+		prev := trace[d-1]
+		k := x - y
+		prevK := k - 1
+		if k == -d || k != d && prev[k-1+d-1] < prev[k+1+d-1] {
+			prevK = k + 1
+		}
+		prevX := prev[prevK+d-1]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, editOp{kind: editEqual, i1: offset + x, i2: offset + y})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, editOp{kind: editInsert, i1: offset + x, i2: offset + y})
+		} else {
+			x--
+			ops = append(ops, editOp{kind: editDelete, i1: offset + x, i2: offset + y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, editOp{kind: editEqual, i1: offset + x, i2: offset + y})
+	}
+	slices.Reverse(ops[start:])
+	return ops
+}
+
+// pairEdits returns the edit script without the equal operations,
+// where the deletions and insertions of a same hunk are paired into replacements.
+//
+// A replacement has the index of the deleted element in i1, and the index of the inserted element in i2.
+func pairEdits(ops []editOp) []editOp {
+	var res []editOp
+	for i := 0; i < len(ops); {
+		if ops[i].kind == editEqual {
+			i++
+			continue
+		}
+		var dels, ins []editOp
+		for ; i < len(ops) && ops[i].kind != editEqual; i++ {
+			if ops[i].kind == editDelete {
+				dels = append(dels, ops[i])
+			} else {
+				ins = append(ins, ops[i])
+			}
+		}
+		n := min(len(dels), len(ins))
+		for j := range n {
+			res = append(res, editOp{kind: editReplace, i1: dels[j].i1, i2: ins[j].i2})
+		}
+		res = append(res, dels[n:]...)
+		res = append(res, ins[n:]...)
+	}
+	return res
+}
+
+// unifiedDiff returns a line-based unified diff of 2 strings, with the given number of context lines.
+//
+// The lines are compared with eq.
+// It returns false if the diff is too expensive to compute.
+func unifiedDiff(s1, s2 string, context int, eq func(l1, l2 string) bool) (string, bool) {
+	lines1 := strings.Split(s1, "\n")
+	lines2 := strings.Split(s2, "\n")
+	ops, ok := editScript(len(lines1), len(lines2), func(i1, i2 int) bool {
+		return eq(lines1[i1], lines2[i2])
+	}, editScriptMaxCost)
+	if !ok {
+		return "", false
+	}
+	var sb strings.Builder
+	for i := 0; i < len(ops); {
+		if ops[i].kind == editEqual {
+			i++
+			continue
+		}
+		start := max(0, i-context)
+		last := i
+		for j := i; j < len(ops) && j-last <= 2*context+1; j++ {
+			if ops[j].kind != editEqual {
+				last = j
+			}
+		}
+		end := min(len(ops), last+context+1)
+		writeUnifiedDiffHunk(&sb, ops[start:end], lines1, lines2)
+		i = end
+	}
+	return sb.String(), true
+}
+
+// writeUnifiedDiffHunk writes a hunk of a unified diff.
+func writeUnifiedDiffHunk(sb *strings.Builder, ops []editOp, lines1, lines2 []string) {
+	count1, count2 := 0, 0
+	for _, op := range ops {
+		if op.kind != editInsert {
+			count1++
+		}
+		if op.kind != editDelete {
+			count2++
+		}
+	}
+	start1, start2 := ops[0].i1, ops[0].i2
+	if count1 > 0 {
+		start1++
+	}
+	if count2 > 0 {
+		start2++
+	}
+	if sb.Len() > 0 {
+		sb.WriteByte('\n')
+	}
+	sb.WriteString("@@ -")
+	sb.WriteString(strconv.Itoa(start1))
+	sb.WriteByte(',')
+	sb.WriteString(strconv.Itoa(count1))
+	sb.WriteString(" +")
+	sb.WriteString(strconv.Itoa(start2))
+	sb.WriteByte(',')
+	sb.WriteString(strconv.Itoa(count2))
+	sb.WriteString(" @@")
+	for _, op := range ops {
+		sb.WriteByte('\n')
+		switch op.kind {
+		case editDelete:
+			sb.WriteByte('-')
+			sb.WriteString(lines1[op.i1])
+		case editInsert:
+			sb.WriteByte('+')
+			sb.WriteString(lines2[op.i2])
+		default:
+			sb.WriteByte(' ')
+			sb.WriteString(lines1[op.i1])
+		}
+	}
+}
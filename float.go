@@ -0,0 +1,98 @@
+package compare
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FloatTolerance represents the tolerance used to compare floats and complexes.
+//
+// 2 floats are equal if they satisfy at least one of the non-zero tolerances.
+// For complexes, the tolerance is applied to the real and imaginary parts separately.
+// An infinite float is only equal to the same infinite float.
+// The zero value means that floats must be strictly equal.
+type FloatTolerance struct {
+	// Abs is the maximum absolute difference: |f1-f2| <= Abs.
+	Abs float64
+	// Rel is the maximum relative difference: |f1-f2| <= Rel*max(|f1|,|f2|).
+	Rel float64
+	// ULP is the maximum distance in units in the last place.
+	// It depends on the size of the float (32 or 64 bits).
+	ULP uint64
+	// NaNEqual makes NaN equal to NaN.
+	NaNEqual bool
+}
+
+func (ft FloatTolerance) isZero() bool {
+	return ft == FloatTolerance{}
+}
+
+// equal returns true if 2 floats are equal within the tolerance.
+func (ft FloatTolerance) equal(f1, f2 float64, bitSize int) bool {
+	if f1 == f2 {
+		return true
+	}
+	nan1 := math.IsNaN(f1)
+	nan2 := math.IsNaN(f2)
+	if nan1 || nan2 {
+		return ft.NaNEqual && nan1 && nan2
+	}
+	if math.IsInf(f1, 0) || math.IsInf(f2, 0) {
+		return false
+	}
+	delta := math.Abs(f1 - f2)
+	if ft.Abs > 0 && delta <= ft.Abs {
+		return true
+	}
+	if ft.Rel > 0 && delta <= ft.Rel*math.Max(math.Abs(f1), math.Abs(f2)) {
+		return true
+	}
+	if ft.ULP > 0 && ulpDistance(f1, f2, bitSize) <= ft.ULP {
+		return true
+	}
+	return false
+}
+
+// ulpDistance returns the distance in units in the last place between 2 floats that are not NaN.
+func ulpDistance(f1, f2 float64, bitSize int) uint64 {
+	var b1, b2 uint64
+	if bitSize == 32 {
+		b1 = orderedFloatBits(uint64(math.Float32bits(float32(f1))), 32)
+		b2 = orderedFloatBits(uint64(math.Float32bits(float32(f2))), 32)
+	} else {
+		b1 = orderedFloatBits(math.Float64bits(f1), 64)
+		b2 = orderedFloatBits(math.Float64bits(f2), 64)
+	}
+	if b1 > b2 {
+		return b1 - b2
+	}
+	return b2 - b1
+}
+
+// orderedFloatBits converts the bits of a float to an integer that has the same order as the float.
+func orderedFloatBits(b uint64, bitSize int) uint64 {
+	signBit := uint64(1) << (bitSize - 1)
+	if b&signBit != 0 {
+		return (signBit<<1 - 1) &^ b
+	}
+	return b | signBit
+}
+
+// String returns the string representation of the non-zero tolerances.
+func (ft FloatTolerance) String() string {
+	var parts []string
+	if ft.Abs > 0 {
+		parts = append(parts, "abs="+strconv.FormatFloat(ft.Abs, 'g', -1, 64))
+	}
+	if ft.Rel > 0 {
+		parts = append(parts, "rel="+strconv.FormatFloat(ft.Rel, 'g', -1, 64))
+	}
+	if ft.ULP > 0 {
+		parts = append(parts, "ulp="+strconv.FormatUint(ft.ULP, 10))
+	}
+	if ft.NaNEqual {
+		parts = append(parts, "nan_equal")
+	}
+	return strings.Join(parts, " ")
+}
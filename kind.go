@@ -0,0 +1,85 @@
+package compare
+
+import (
+	"fmt"
+)
+
+// DifferenceKind represents the kind of a [Difference].
+//
+// It allows to filter, count or route the differences without parsing the message.
+// It is encoded as a string in JSON.
+type DifferenceKind uint8
+
+const (
+	// DifferenceCustom is a difference reported by a custom [Func].
+	// It is the zero value.
+	DifferenceCustom DifferenceKind = iota
+	// DifferenceOnlyOneValid means that only one of the values is valid.
+	DifferenceOnlyOneValid
+	// DifferenceOnlyOneNil means that only one of the values is nil.
+	DifferenceOnlyOneNil
+	// DifferenceTypeMismatch means that the types are not equal.
+	DifferenceTypeMismatch
+	// DifferenceValueMismatch means that the values are not equal (bool, number, string, bytes, pointer, ...).
+	DifferenceValueMismatch
+	// DifferenceLengthMismatch means that the lengths are not equal.
+	DifferenceLengthMismatch
+	// DifferenceCapacityMismatch means that the capacities are not equal.
+	DifferenceCapacityMismatch
+	// DifferenceKeyMissing means that a map key or a slice key (see [SliceKey]) is defined in only one of the values.
+	DifferenceKeyMissing
+	// DifferenceElementMissing means that a slice element is defined in only one of the values
+	// (see [Comparator.SliceUnordered] and [Comparator.SliceEditScript]).
+	DifferenceElementMissing
+	// DifferenceMethodEqualFalse means that the method .Equal() returned false.
+	DifferenceMethodEqualFalse
+	// DifferenceMethodCmpNotEqual means that the method .Cmp() returned a non-zero value.
+	DifferenceMethodCmpNotEqual
+	// DifferenceTruncated means that the comparison was stopped (max depth or max differences reached).
+	DifferenceTruncated
+	// DifferenceMethodCompareNotEqual means that the method .Compare() returned a non-zero value.
+	DifferenceMethodCompareNotEqual
+)
+
+var differenceKindStrings = [...]string{
+	DifferenceCustom:                "custom",
+	DifferenceOnlyOneValid:          "only_one_valid",
+	DifferenceOnlyOneNil:            "only_one_nil",
+	DifferenceTypeMismatch:          "type_mismatch",
+	DifferenceValueMismatch:         "value_mismatch",
+	DifferenceLengthMismatch:        "length_mismatch",
+	DifferenceCapacityMismatch:      "capacity_mismatch",
+	DifferenceKeyMissing:            "key_missing",
+	DifferenceElementMissing:        "element_missing",
+	DifferenceMethodEqualFalse:      "method_equal_false",
+	DifferenceMethodCmpNotEqual:     "method_cmp_not_equal",
+	DifferenceTruncated:             "truncated",
+	DifferenceMethodCompareNotEqual: "method_compare_not_equal",
+}
+
+// String implements [fmt.Stringer].
+func (k DifferenceKind) String() string {
+	if int(k) < len(differenceKindStrings) {
+		return differenceKindStrings[k]
+	}
+	return fmt.Sprintf("DifferenceKind(%d)", uint8(k))
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (k DifferenceKind) MarshalText() ([]byte, error) {
+	if int(k) >= len(differenceKindStrings) {
+		return nil, fmt.Errorf("invalid DifferenceKind %d", uint8(k))
+	}
+	return []byte(differenceKindStrings[k]), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (k *DifferenceKind) UnmarshalText(text []byte) error {
+	for i, s := range differenceKindStrings {
+		if s == string(text) {
+			*k = DifferenceKind(i) //nolint:gosec // The index is in the range of the array.
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid DifferenceKind %q", text)
+}
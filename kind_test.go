@@ -0,0 +1,40 @@
+package compare_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/assert/assertauto"
+	. "github.com/pierrre/compare"
+)
+
+func TestDifferenceKindString(t *testing.T) {
+	assert.Equal(t, DifferenceValueMismatch.String(), "value_mismatch")
+	assert.Equal(t, DifferenceKind(255).String(), "DifferenceKind(255)")
+}
+
+func TestDifferenceKindJSON(t *testing.T) {
+	d := Difference{
+		Kind:    DifferenceKeyMissing,
+		Message: "map key not defined",
+	}
+	b, err := json.Marshal(d)
+	assert.NoError(t, err)
+	assertauto.Equal(t, string(b))
+	var d2 Difference
+	err = json.Unmarshal(b, &d2)
+	assert.NoError(t, err)
+	assert.Equal(t, d2.Kind, DifferenceKeyMissing)
+}
+
+func TestDifferenceKindMarshalTextError(t *testing.T) {
+	_, err := DifferenceKind(255).MarshalText()
+	assert.Error(t, err)
+}
+
+func TestDifferenceKindUnmarshalTextError(t *testing.T) {
+	var k DifferenceKind
+	err := k.UnmarshalText([]byte("invalid"))
+	assert.Error(t, err)
+}
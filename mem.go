@@ -0,0 +1,70 @@
+package compare
+
+import (
+	"bytes"
+	"reflect"
+	"unsafe" //nolint:depguard // Used to compare the memory of values without pointers and padding.
+
+	"github.com/pierrre/go-libs/syncutil"
+)
+
+var memComparableCache syncutil.Map[reflect.Type, bool]
+
+// isMemComparable returns true if the values of the type can be compared by their memory.
+//
+// It is true for bools, integers, and arrays and structs composed only of them, without padding.
+func isMemComparable(typ reflect.Type) bool {
+	mc, ok := memComparableCache.Load(typ)
+	if !ok {
+		mc, _ = memComparableCache.LoadOrStore(typ, computeMemComparable(typ))
+	}
+	return mc
+}
+
+func computeMemComparable(typ reflect.Type) bool {
+	switch typ.Kind() { //nolint:exhaustive // The other kinds are not memory comparable.
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	case reflect.Array:
+		return computeMemComparable(typ.Elem())
+	case reflect.Struct:
+		var size uintptr
+		for i := range typ.NumField() {
+			f := typ.Field(i)
+			if !computeMemComparable(f.Type) {
+				return false
+			}
+			size += f.Type.Size()
+		}
+		return size == typ.Size() // No padding.
+	}
+	return false
+}
+
+// memEqual returns true if 2 values of a memory comparable type (see [isMemComparable]) are equal.
+//
+// The addressable values are compared by their memory, in order to avoid a copy.
+// The other values are compared with ==.
+func memEqual(v1, v2 reflect.Value) bool {
+	if v1.CanAddr() && v2.CanAddr() {
+		return bytes.Equal(memBytes(v1), memBytes(v2))
+	}
+	if v1.CanInterface() && v2.CanInterface() {
+		return v1.Interface() == v2.Interface()
+	}
+	return false
+}
+
+// memBytes returns the memory of an addressable value.
+func memBytes(v reflect.Value) []byte {
+	return unsafe.Slice((*byte)(v.Addr().UnsafePointer()), v.Type().Size())
+}
+
+// isMemEqual returns true if the fast path can be used and the values are equal.
+//
+// It is not used if [Comparator.MaxDepth] is set, because the max depth must be reported even for equal values.
+func (c *Comparator) isMemEqual(v1, v2 reflect.Value) bool {
+	return c.MaxDepth <= 0 && isMemComparable(v1.Type()) && memEqual(v1, v2)
+}
@@ -0,0 +1,194 @@
+package compare
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Option represents an option for [NewComparator] and [Comparator.With].
+type Option func(c *Comparator)
+
+// WithMaxDepth returns an [Option] that sets [Comparator.MaxDepth].
+func WithMaxDepth(maxDepth int) Option {
+	return func(c *Comparator) {
+		c.MaxDepth = maxDepth
+	}
+}
+
+// WithSliceMaxDifferences returns an [Option] that sets [Comparator.SliceMaxDifferences].
+func WithSliceMaxDifferences(maxDifferences int) Option {
+	return func(c *Comparator) {
+		c.SliceMaxDifferences = maxDifferences
+	}
+}
+
+// WithMapMaxDifferences returns an [Option] that sets [Comparator.MapMaxDifferences].
+func WithMapMaxDifferences(maxDifferences int) Option {
+	return func(c *Comparator) {
+		c.MapMaxDifferences = maxDifferences
+	}
+}
+
+// WithMaxDifferences returns an [Option] that sets [Comparator.MaxDifferences].
+func WithMaxDifferences(maxDifferences int) Option {
+	return func(c *Comparator) {
+		c.MaxDifferences = maxDifferences
+	}
+}
+
+// WithSliceUnordered returns an [Option] that sets [Comparator.SliceUnordered].
+func WithSliceUnordered(unordered bool) Option {
+	return func(c *Comparator) {
+		c.SliceUnordered = unordered
+	}
+}
+
+// WithUnorderedTypes returns an [Option] that adds types to [Comparator.UnorderedTypes].
+func WithUnorderedTypes(typs ...reflect.Type) Option {
+	return func(c *Comparator) {
+		if c.UnorderedTypes == nil {
+			c.UnorderedTypes = make(map[reflect.Type]bool, len(typs))
+		}
+		for _, typ := range typs {
+			c.UnorderedTypes[typ] = true
+		}
+	}
+}
+
+// WithUnorderedPaths returns an [Option] that adds [PathPattern]s to [Comparator.UnorderedPaths].
+//
+// The patterns are parsed with [MustParsePathPattern], so it panics if a pattern is invalid.
+func WithUnorderedPaths(patterns ...string) Option {
+	return func(c *Comparator) {
+		for _, s := range patterns {
+			c.UnorderedPaths = append(c.UnorderedPaths, MustParsePathPattern(s))
+		}
+	}
+}
+
+// WithSliceKey returns an [Option] that adds a [SliceKey] for the element type T to [Comparator.SliceKeys].
+//
+// The name is used in the path, for example "[id=42]".
+func WithSliceKey[T any, K comparable](name string, f func(T) K) Option {
+	return func(c *Comparator) {
+		if c.SliceKeys == nil {
+			c.SliceKeys = make(map[reflect.Type]SliceKey)
+		}
+		c.SliceKeys[reflect.TypeFor[T]()] = SliceKey{
+			Name: name,
+			Func: func(v reflect.Value) any {
+				e, _ := reflect.TypeAssert[T](v)
+				return f(e)
+			},
+		}
+	}
+}
+
+// WithSliceEditScript returns an [Option] that sets [Comparator.SliceEditScript].
+func WithSliceEditScript(editScript bool) Option {
+	return func(c *Comparator) {
+		c.SliceEditScript = editScript
+	}
+}
+
+// WithStringMaxLength returns an [Option] that sets [Comparator.StringMaxLength].
+func WithStringMaxLength(maxLength int) Option {
+	return func(c *Comparator) {
+		c.StringMaxLength = maxLength
+	}
+}
+
+// WithStringDiff returns an [Option] that sets [Comparator.StringDiff].
+func WithStringDiff(diff bool) Option {
+	return func(c *Comparator) {
+		c.StringDiff = diff
+	}
+}
+
+// WithStringDiffContext returns an [Option] that sets [Comparator.StringDiffContext].
+func WithStringDiffContext(context int) Option {
+	return func(c *Comparator) {
+		c.StringDiffContext = context
+	}
+}
+
+// WithBytesDiff returns an [Option] that sets [Comparator.BytesDiff].
+func WithBytesDiff(diff bool) Option {
+	return func(c *Comparator) {
+		c.BytesDiff = diff
+	}
+}
+
+// WithNilEmptyEqual returns an [Option] that sets [Comparator.NilEmptyEqual].
+func WithNilEmptyEqual(equal bool) Option {
+	return func(c *Comparator) {
+		c.NilEmptyEqual = equal
+	}
+}
+
+// WithNilEmptyEqualType returns an [Option] that sets the value for a type in [Comparator.NilEmptyEqualTypes].
+func WithNilEmptyEqualType(typ reflect.Type, equal bool) Option {
+	return func(c *Comparator) {
+		if c.NilEmptyEqualTypes == nil {
+			c.NilEmptyEqualTypes = make(map[reflect.Type]bool)
+		}
+		c.NilEmptyEqualTypes[typ] = equal
+	}
+}
+
+// WithNilZeroEqual returns an [Option] that sets [Comparator.NilZeroEqual].
+func WithNilZeroEqual(equal bool) Option {
+	return func(c *Comparator) {
+		c.NilZeroEqual = equal
+	}
+}
+
+// WithFloatTolerance returns an [Option] that sets [Comparator.FloatTolerance].
+func WithFloatTolerance(ft FloatTolerance) Option {
+	return func(c *Comparator) {
+		c.FloatTolerance = ft
+	}
+}
+
+// WithFuncs returns an [Option] that replaces [Comparator.Funcs].
+func WithFuncs(fs ...Func) Option {
+	return func(c *Comparator) {
+		c.Funcs = slices.Clone(fs)
+	}
+}
+
+// WithPrependFunc returns an [Option] that adds a [Func] before [Comparator.Funcs].
+//
+// It is called before the existing functions.
+func WithPrependFunc(f Func) Option {
+	return func(c *Comparator) {
+		c.Funcs = slices.Insert(c.Funcs, 0, f)
+	}
+}
+
+// WithAppendFunc returns an [Option] that adds a [Func] after [Comparator.Funcs].
+//
+// It is called after the existing functions.
+func WithAppendFunc(f Func) Option {
+	return func(c *Comparator) {
+		c.Funcs = append(c.Funcs, f)
+	}
+}
+
+// WithRegisterType returns an [Option] that calls [RegisterType].
+func WithRegisterType[T any](f func(c *Comparator, st *State, v1, v2 T) Result) Option {
+	return func(c *Comparator) {
+		RegisterType(c, f)
+	}
+}
+
+// WithIgnorePaths returns an [Option] that adds [PathPattern]s to [Comparator.IgnorePaths].
+//
+// The patterns are parsed with [MustParsePathPattern], so it panics if a pattern is invalid.
+func WithIgnorePaths(patterns ...string) Option {
+	return func(c *Comparator) {
+		for _, s := range patterns {
+			c.IgnorePaths = append(c.IgnorePaths, MustParsePathPattern(s))
+		}
+	}
+}
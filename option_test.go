@@ -0,0 +1,56 @@
+package compare_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pierrre/assert"
+	. "github.com/pierrre/compare"
+)
+
+func TestNewComparatorOptions(t *testing.T) {
+	c := NewComparator(
+		WithMaxDepth(1),
+		WithSliceMaxDifferences(2),
+		WithMapMaxDifferences(3),
+		WithMaxDifferences(4),
+	)
+	assert.Equal(t, c.MaxDepth, 1)
+	assert.Equal(t, c.SliceMaxDifferences, 2)
+	assert.Equal(t, c.MapMaxDifferences, 3)
+	assert.Equal(t, c.MaxDifferences, 4)
+	assert.SliceLen(t, c.Funcs, len(NewComparator().Funcs))
+}
+
+func TestComparatorWith(t *testing.T) {
+	c := NewComparator()
+	nc := c.With(WithMaxDepth(1), WithPrependFunc(testFuncAlwaysEqual), WithAppendFunc(testFuncAlwaysEqual))
+	assert.Equal(t, c.MaxDepth, 0)
+	assert.Equal(t, nc.MaxDepth, 1)
+	assert.SliceLen(t, c.Funcs, 5)
+	assert.SliceLen(t, nc.Funcs, 7)
+	assert.SliceEmpty(t, nc.Compare(1, 2))
+	assert.SliceNotEmpty(t, c.Compare(1, 2))
+}
+
+func TestWithFuncs(t *testing.T) {
+	fs := []Func{testFuncAlwaysEqual}
+	c := NewComparator(WithFuncs(fs...))
+	assert.SliceLen(t, c.Funcs, 1)
+	fs[0] = nil
+	assert.True(t, c.Funcs[0] != nil)
+	assert.SliceEmpty(t, c.Compare(1, 2))
+}
+
+func TestWithRegisterType(t *testing.T) {
+	c := NewComparator(WithRegisterType(func(c *Comparator, st *State, v1, v2 int) Result {
+		return nil
+	}))
+	assert.MapLen(t, c.TypeFuncs, 1)
+	assert.SliceEmpty(t, c.Compare(1, 2))
+	assert.SliceNotEmpty(t, c.Compare("a", "b"))
+}
+
+func testFuncAlwaysEqual(c *Comparator, st *State, v1, v2 reflect.Value) (Result, bool) {
+	return nil, true
+}
@@ -0,0 +1,192 @@
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PathPattern is a pattern that matches [Path]s.
+//
+// It uses the same notation as [Path.Format]:
+//   - ".Name" matches a struct field, ".*" matches any struct field
+//   - "[key]" matches a map key or a slice/array index, "[*]" matches any map key or index
+//   - "**" matches zero or more elements
+//   - "." alone matches the root
+//
+// For example: ".Users[*].UpdatedAt", ".Meta[*]" or "**.ID".
+//
+// It should be created with [ParsePathPattern].
+type PathPattern struct {
+	s     string
+	elems []pathPatternElem
+}
+
+type pathPatternElemKind int
+
+const (
+	pathPatternElemStruct pathPatternElemKind = iota
+	pathPatternElemBracket
+	pathPatternElemAny
+)
+
+type pathPatternElem struct {
+	kind     pathPatternElemKind
+	wildcard bool
+	value    string
+	index    int
+	isIndex  bool
+}
+
+// ParsePathPattern parses a [PathPattern].
+func ParsePathPattern(s string) (*PathPattern, error) {
+	p := &PathPattern{
+		s: s,
+	}
+	if s == "." {
+		return p, nil
+	}
+	for rest := s; rest != ""; {
+		e, r, err := parsePathPatternElem(rest)
+		if err != nil {
+			return nil, fmt.Errorf("parse path pattern %q: %w", s, err)
+		}
+		p.elems = append(p.elems, e)
+		rest = r
+	}
+	if len(p.elems) == 0 {
+		return nil, fmt.Errorf("parse path pattern %q: %w", s, errors.New("empty"))
+	}
+	return p, nil
+}
+
+func parsePathPatternElem(s string) (pathPatternElem, string, error) {
+	switch {
+	case strings.HasPrefix(s, ".**"):
+		return pathPatternElem{kind: pathPatternElemAny}, s[3:], nil
+	case strings.HasPrefix(s, "**"):
+		return pathPatternElem{kind: pathPatternElemAny}, s[2:], nil
+	case strings.HasPrefix(s, "."):
+		s = s[1:]
+		i := strings.IndexAny(s, ".[")
+		if i < 0 {
+			i = len(s)
+		}
+		name := s[:i]
+		if name == "" {
+			return pathPatternElem{}, "", errors.New("empty struct field name")
+		}
+		return pathPatternElem{
+			kind:     pathPatternElemStruct,
+			wildcard: name == "*",
+			value:    name,
+		}, s[i:], nil
+	case strings.HasPrefix(s, "["):
+		s = s[1:]
+		i := strings.IndexByte(s, ']')
+		if i < 0 {
+			return pathPatternElem{}, "", errors.New("missing ']'")
+		}
+		value := s[:i]
+		index, err := strconv.Atoi(value)
+		return pathPatternElem{
+			kind:     pathPatternElemBracket,
+			wildcard: value == "*",
+			value:    value,
+			index:    index,
+			isIndex:  err == nil,
+		}, s[i+1:], nil
+	default:
+		return pathPatternElem{}, "", fmt.Errorf("unexpected character %q", s[0])
+	}
+}
+
+// MustParsePathPattern calls [ParsePathPattern] and panics on error.
+func MustParsePathPattern(s string) *PathPattern {
+	p, err := ParsePathPattern(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the string representation of the [PathPattern].
+func (p *PathPattern) String() string {
+	return p.s
+}
+
+// Match returns true if the [PathPattern] matches the [Path].
+func (p *PathPattern) Match(path Path) bool {
+	return matchPathPattern(p.elems, path, 0)
+}
+
+// pathPatternTarget is a list of path elements that can be matched by a [PathPattern].
+type pathPatternTarget interface {
+	len() int
+	matchElem(e pathPatternElem, i int) bool
+}
+
+func matchPathPattern[T pathPatternTarget](es []pathPatternElem, t T, i int) bool {
+	for len(es) > 0 {
+		e := es[0]
+		es = es[1:]
+		if e.kind == pathPatternElemAny {
+			for k := t.len(); k >= i; k-- {
+				if matchPathPattern(es, t, k) {
+					return true
+				}
+			}
+			return false
+		}
+		if i >= t.len() || !t.matchElem(e, i) {
+			return false
+		}
+		i++
+	}
+	return i == t.len()
+}
+
+func (p Path) len() int {
+	return len(p)
+}
+
+func (p Path) matchElem(e pathPatternElem, i int) bool {
+	pe := p[len(p)-1-i] // Path elements are stored in reverse order.
+	switch e.kind {
+	case pathPatternElemStruct:
+		return pe.Struct != nil && (e.wildcard || *pe.Struct == e.value)
+	case pathPatternElemBracket:
+		switch {
+		case pe.Map != nil:
+			return e.wildcard || *pe.Map == e.value
+		case pe.Index != nil:
+			return e.wildcard || e.isIndex && *pe.Index == e.index
+		}
+	}
+	return false
+}
+
+type pathSteps []pathStep
+
+func (ps pathSteps) len() int {
+	return len(ps)
+}
+
+func (ps pathSteps) matchElem(e pathPatternElem, i int) bool {
+	s := ps[i]
+	switch e.kind {
+	case pathPatternElemStruct:
+		return s.structType != nil && (e.wildcard || s.structType.Field(s.index).Name == e.value)
+	case pathPatternElemBracket:
+		switch {
+		case s.structType != nil:
+			return false
+		case s.mapKey.IsValid():
+			return e.wildcard || s.mapKeyString() == e.value
+		default:
+			return e.wildcard || e.isIndex && s.index == e.index
+		}
+	}
+	return false
+}
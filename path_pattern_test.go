@@ -0,0 +1,147 @@
+package compare_test
+
+import (
+	"testing"
+
+	"github.com/pierrre/assert"
+	. "github.com/pierrre/compare"
+)
+
+var pathPatternTestCases = []struct {
+	name    string
+	pattern string
+	path    Path
+	match   bool
+}{
+	{
+		name:    "Root",
+		pattern: ".",
+		match:   true,
+	},
+	{
+		name:    "RootNotMatch",
+		pattern: ".",
+		path:    Path{{Struct: new("A")}},
+	},
+	{
+		name:    "Struct",
+		pattern: ".A",
+		path:    Path{{Struct: new("A")}},
+		match:   true,
+	},
+	{
+		name:    "StructNotMatch",
+		pattern: ".A",
+		path:    Path{{Struct: new("B")}},
+	},
+	{
+		name:    "StructWildcard",
+		pattern: ".*",
+		path:    Path{{Struct: new("A")}},
+		match:   true,
+	},
+	{
+		name:    "StructNotMatchMap",
+		pattern: ".A",
+		path:    Path{{Map: new("A")}},
+	},
+	{
+		name:    "Map",
+		pattern: "[a]",
+		path:    Path{{Map: new("a")}},
+		match:   true,
+	},
+	{
+		name:    "Index",
+		pattern: "[1]",
+		path:    Path{{Index: new(1)}},
+		match:   true,
+	},
+	{
+		name:    "IndexNotMatch",
+		pattern: "[1]",
+		path:    Path{{Index: new(2)}},
+	},
+	{
+		name:    "BracketWildcard",
+		pattern: "[*]",
+		path:    Path{{Index: new(1)}},
+		match:   true,
+	},
+	{
+		name:    "Nested",
+		pattern: ".Users[*].UpdatedAt",
+		path:    Path{{Struct: new("UpdatedAt")}, {Index: new(3)}, {Struct: new("Users")}},
+		match:   true,
+	},
+	{
+		name:    "NestedTooShort",
+		pattern: ".Users[*].UpdatedAt",
+		path:    Path{{Index: new(3)}, {Struct: new("Users")}},
+	},
+	{
+		name:    "NestedTooLong",
+		pattern: ".Users[*]",
+		path:    Path{{Struct: new("UpdatedAt")}, {Index: new(3)}, {Struct: new("Users")}},
+	},
+	{
+		name:    "AnyPrefix",
+		pattern: "**.ID",
+		path:    Path{{Struct: new("ID")}, {Index: new(3)}, {Struct: new("Users")}},
+		match:   true,
+	},
+	{
+		name:    "AnyPrefixEmpty",
+		pattern: "**.ID",
+		path:    Path{{Struct: new("ID")}},
+		match:   true,
+	},
+	{
+		name:    "AnySuffix",
+		pattern: ".Users.**",
+		path:    Path{{Struct: new("ID")}, {Index: new(3)}, {Struct: new("Users")}},
+		match:   true,
+	},
+	{
+		name:    "AnyMiddle",
+		pattern: ".A.**.B",
+		path:    Path{{Struct: new("B")}, {Map: new("x")}, {Struct: new("C")}, {Struct: new("A")}},
+		match:   true,
+	},
+	{
+		name:    "AnyNotMatch",
+		pattern: "**.ID",
+		path:    Path{{Struct: new("Name")}, {Struct: new("ID")}},
+	},
+}
+
+func TestPathPatternMatch(t *testing.T) {
+	for _, tc := range pathPatternTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := MustParsePathPattern(tc.pattern)
+			assert.Equal(t, p.String(), tc.pattern)
+			assert.Equal(t, p.Match(tc.path), tc.match)
+		})
+	}
+}
+
+func TestParsePathPatternError(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"A",
+		".A.",
+		"[a",
+		".A[0]x",
+	} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParsePathPattern(s)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMustParsePathPatternPanic(t *testing.T) {
+	assert.Panics(t, func() {
+		MustParsePathPattern("[")
+	})
+}
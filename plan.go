@@ -0,0 +1,79 @@
+package compare
+
+import (
+	"reflect"
+)
+
+// typePlan is the comparison plan of a type.
+//
+// It contains the decisions that depend only on the type.
+// It is built once per type, and cached by frozen [Comparator]s (see [Comparator.Freeze]).
+type typePlan struct {
+	// funcs contains the function of [Comparator.TypeFuncs], and the [Comparator.Funcs] that may apply to the type.
+	funcs []Func
+	// compareKind compares the values according to their kind.
+	compareKind func(c *Comparator, st *State, v1, v2 reflect.Value)
+}
+
+// getPlan returns the plan of a type, or nil if the [Comparator] is not frozen.
+func (c *Comparator) getPlan(typ reflect.Type) *typePlan {
+	if c.plans == nil {
+		return nil
+	}
+	p, ok := c.plans.Load(typ)
+	if !ok {
+		p, _ = c.plans.LoadOrStore(typ, c.newPlan(typ))
+	}
+	return p
+}
+
+func (c *Comparator) newPlan(typ reflect.Type) *typePlan {
+	p := &typePlan{
+		compareKind: getKindCompareFunc(typ.Kind()),
+	}
+	if f, ok := c.TypeFuncs[typ]; ok {
+		p.funcs = append(p.funcs, f)
+	}
+	for _, f := range c.Funcs {
+		if funcMayApply(f, typ) {
+			p.funcs = append(p.funcs, f)
+		}
+	}
+	return p
+}
+
+// compare compares 2 values of the type.
+//
+// It is equivalent to [Comparator.compareFuncs] followed by [Comparator.compareKind].
+func (p *typePlan) compare(c *Comparator, st *State, v1, v2 reflect.Value) {
+	for _, f := range p.funcs {
+		if r, stop := f(c, st, v1, v2); stop {
+			st.reportResult(r)
+			return
+		}
+	}
+	p.compareKind(c, st, v1, v2)
+}
+
+// funcMayApply returns true if the [Func] may apply to the type.
+//
+// The built-in functions only apply to some types.
+// The other functions may apply to any type.
+func funcMayApply(f Func, typ reflect.Type) bool {
+	switch reflect.ValueOf(f).Pointer() {
+	case reflect.ValueOf(compareBytesEqual).Pointer():
+		return isBytesType(typ)
+	case reflect.ValueOf(compareReflectValue).Pointer():
+		return typ == typeReflectValue
+	case reflect.ValueOf(compareMethodEqual).Pointer():
+		_, ok := getMethodEqualFunc(typ)
+		return ok
+	case reflect.ValueOf(compareMethodCmp).Pointer():
+		_, ok := getMethodCmpFunc(typ)
+		return ok
+	case reflect.ValueOf(compareMethodCompare).Pointer():
+		_, ok := getMethodCompareFunc(typ)
+		return ok
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package compare
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// firstStringDifference returns the byte offset of the first different rune of 2 strings.
+//
+// The strings must be different.
+func firstStringDifference(s1, s2 string) int {
+	i := 0
+	for i < len(s1) && i < len(s2) && s1[i] == s2[i] {
+		i++
+	}
+	for i > 0 && i < len(s1) && !utf8.RuneStart(s1[i]) {
+		i--
+	}
+	return i
+}
+
+// quoteStringWindow returns the quoted window of maxLength bytes of a string around the offset.
+//
+// The truncated parts are replaced by an ellipsis.
+func quoteStringWindow(s string, offset int, maxLength int) string {
+	if len(s) <= maxLength {
+		return strconv.Quote(s)
+	}
+	start := max(0, offset-maxLength/2)
+	end := min(len(s), start+maxLength)
+	start = max(0, end-maxLength)
+	for start < end && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	for end < len(s) && end > start && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	res := strconv.Quote(s[start:end])
+	if start > 0 {
+		res = stringEllipsis + res
+	}
+	if end < len(s) {
+		res += stringEllipsis
+	}
+	return res
+}
+
+const stringEllipsis = "..."
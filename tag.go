@@ -0,0 +1,88 @@
+package compare
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pierrre/go-libs/syncutil"
+)
+
+// TagName is the name of the struct tag used to customize the comparison of struct fields.
+//
+// The tag value is a comma separated list of options:
+//   - "-": the field is ignored
+//   - "nocase": strings are compared case-insensitively (see [strings.EqualFold])
+//   - "approx=<epsilon>": floats and complexes are compared with [FloatTolerance.Abs] set to epsilon
+//   - "unordered": slices and arrays are compared as multisets (see [Comparator.SliceUnordered])
+//
+// The options apply to the field value and the values it contains (slice elements, map values, ...),
+// but not to the fields of nested structs, which use their own tags.
+// Unknown options are ignored.
+const TagName = "compare"
+
+// fieldOptions represents the options of a struct field, parsed from the [TagName] tag.
+type fieldOptions struct {
+	skip      bool
+	noCase    bool
+	approx    float64
+	unordered bool
+}
+
+// structField is a struct field to compare, with its options.
+type structField struct {
+	index   int
+	options fieldOptions
+}
+
+var structFieldsCache syncutil.Map[reflect.Type, []structField]
+
+// getStructFields returns the fields of a struct type that must be compared.
+//
+// The fields skipped with the "-" tag are excluded.
+func getStructFields(typ reflect.Type) []structField {
+	fs, ok := structFieldsCache.Load(typ)
+	if ok {
+		return fs
+	}
+	n := typ.NumField()
+	fs = make([]structField, 0, n)
+	for i := range n {
+		var fo fieldOptions
+		if tag, ok := typ.Field(i).Tag.Lookup(TagName); ok {
+			fo = parseFieldOptions(tag)
+		}
+		if fo.skip {
+			continue
+		}
+		fs = append(fs, structField{
+			index:   i,
+			options: fo,
+		})
+	}
+	fs, _ = structFieldsCache.LoadOrStore(typ, fs)
+	return fs
+}
+
+func parseFieldOptions(tag string) fieldOptions {
+	var fo fieldOptions
+	if tag == "-" {
+		fo.skip = true
+		return fo
+	}
+	for opt := range strings.SplitSeq(tag, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch name {
+		case "nocase":
+			fo.noCase = true
+		case "approx":
+			approx, err := strconv.ParseFloat(value, 64)
+			if err == nil {
+				fo.approx = approx
+			}
+		case "unordered":
+			fo.unordered = true
+		}
+	}
+	return fo
+}